@@ -0,0 +1,212 @@
+package bayaan
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Audit records are JSONFormatter objects extended with two keys:
+//
+//	{...,"audit_prev":"<hash of the previous record>","audit_hash":"<hash>"}
+//
+// audit_hash is the SHA-256, or HMAC-SHA-256 when a key is set, of every
+// byte of the line before `,"audit_hash":`, so changing, inserting,
+// removing or reordering records breaks the chain from that point on.
+const (
+	auditPrevKey = `,"audit_prev":"`
+	auditHashKey = `,"audit_hash":"`
+	auditHashLen = sha256.Size * 2
+)
+
+// auditGenesis is the audit_prev of the first record of a chain.
+var auditGenesis = strings.Repeat("0", auditHashLen)
+
+// AuditSink writes entries as a tamper-evident, hash-chained JSON lines
+// log. Check a log with VerifyAudit. Truncating the end of a log leaves a
+// valid chain, so record Head somewhere else from time to time when that
+// matters.
+type AuditSink struct {
+	mu        sync.Mutex
+	w         io.Writer
+	closer    io.Closer
+	key       []byte
+	prev      string
+	formatter JSONFormatter
+}
+
+// NewAuditSink returns an AuditSink writing to w. A non-empty key makes
+// the hashes HMACs, which cannot be recomputed by someone editing the log
+// without the key. prev continues an existing chain from its last hash;
+// empty starts a new one.
+func NewAuditSink(w io.Writer, key []byte, prev string) *AuditSink {
+	if prev == "" {
+		prev = auditGenesis
+	}
+	return &AuditSink{w: w, key: key, prev: prev}
+}
+
+// OpenAuditFile opens the audit log at path for appending, continuing its
+// chain, or creates it.
+func OpenAuditFile(path string, key []byte) (*AuditSink, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	prev := ""
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 64<<20)
+	for line := 1; scanner.Scan(); line++ {
+		_, h, ok := splitAuditLine(scanner.Bytes())
+		if !ok {
+			f.Close()
+			return nil, &AuditError{Line: line, Reason: "malformed record"}
+		}
+		prev = h
+	}
+	if err := scanner.Err(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	s := NewAuditSink(f, key, prev)
+	s.closer = f
+	return s, nil
+}
+
+// WithAuditFile adds an AuditSink appending to the file at path. Opening
+// failures are reported through the diagnostics writer.
+func WithAuditFile(path string, key []byte) LoggerOption {
+	return func(l *Logger) {
+		s, err := OpenAuditFile(path, key)
+		if err != nil {
+			l.warnf("Logger could not open audit log %s: %v", path, err)
+			return
+		}
+		l.addOutput(output{sink: s, closer: s}, true)
+	}
+}
+
+func (s *AuditSink) WriteEntry(e *Entry) error {
+	// Fields named like the chain keys are prefixed, as JSONFormatter does
+	// for its own keys, so every record has one audit_prev and audit_hash.
+	for _, key := range []string{"audit_prev", "audit_hash"} {
+		if value, ok := e.Fields[key]; ok {
+			copied := *e
+			copied.Fields = make(Fields, len(e.Fields))
+			for k, v := range e.Fields {
+				copied.Fields[k] = v
+			}
+			delete(copied.Fields, key)
+			copied.Fields["fields."+key] = value
+			e = &copied
+		}
+	}
+	record, err := s.formatter.Format(e)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	line := append(record[:len(record)-1], auditPrevKey...)
+	line = append(line, s.prev...)
+	line = append(line, '"')
+	sum := auditSum(s.key, line)
+	line = append(line, auditHashKey...)
+	line = append(line, sum...)
+	line = append(line, "\"}\n"...)
+	if _, err := s.w.Write(line); err != nil {
+		return err
+	}
+	s.prev = sum
+	return nil
+}
+
+// Head returns the hash of the last record written, which any later
+// verification must reach.
+func (s *AuditSink) Head() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.prev
+}
+
+func (s *AuditSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+// AuditError reports where an audit log fails verification.
+type AuditError struct {
+	Line   int
+	Reason string
+}
+
+func (e *AuditError) Error() string {
+	return fmt.Sprintf("bayaan: audit log line %d: %s", e.Line, e.Reason)
+}
+
+// VerifyAudit checks every record of an audit log against its hash and its
+// predecessor, and returns the hash of the last record. Verification
+// starts from a new chain, so r must hold the log from its first record.
+// A failure is reported as an *AuditError.
+func VerifyAudit(r io.Reader, key []byte) (head string, err error) {
+	prev := auditGenesis
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64<<20)
+	for line := 1; scanner.Scan(); line++ {
+		body, h, ok := splitAuditLine(scanner.Bytes())
+		if !ok {
+			return "", &AuditError{Line: line, Reason: "malformed record"}
+		}
+		i := bytes.LastIndex(body, []byte(auditPrevKey))
+		if i < 0 || string(body[i+len(auditPrevKey):len(body)-1]) != prev {
+			return "", &AuditError{Line: line, Reason: "does not follow the previous record"}
+		}
+		if !hmac.Equal([]byte(auditSum(key, body)), []byte(h)) {
+			return "", &AuditError{Line: line, Reason: "hash mismatch"}
+		}
+		prev = h
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return prev, nil
+}
+
+// splitAuditLine separates a record into the hashed part and its hash.
+func splitAuditLine(line []byte) (body []byte, sum string, ok bool) {
+	n := len(auditHashKey) + auditHashLen + 2
+	if len(line) < n || !bytes.HasSuffix(line, []byte(`"}`)) {
+		return nil, "", false
+	}
+	tail := line[len(line)-n:]
+	if !bytes.HasPrefix(tail, []byte(auditHashKey)) {
+		return nil, "", false
+	}
+	body = line[:len(line)-n]
+	if !bytes.HasSuffix(body, []byte(`"`)) {
+		return nil, "", false
+	}
+	return body, string(tail[len(auditHashKey) : len(auditHashKey)+auditHashLen]), true
+}
+
+func auditSum(key, data []byte) string {
+	var h hash.Hash
+	if len(key) > 0 {
+		h = hmac.New(sha256.New, key)
+	} else {
+		h = sha256.New()
+	}
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
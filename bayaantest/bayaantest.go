@@ -0,0 +1,147 @@
+// Package bayaantest records the entries a Logger writes, so tests can
+// assert on levels, messages and fields instead of scraping formatted text:
+//
+//	func TestCharge(t *testing.T) {
+//		logger, rec := bayaantest.NewLogger()
+//		charge(logger, 42)
+//		rec.AssertLogged(t, bayaan.LoggerLevelInfo, "charged", bayaan.Fields{"amount": 42})
+//	}
+package bayaantest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ahmedsat/bayaan"
+)
+
+// Recorder is a Sink keeping every entry it receives in memory. It is safe
+// for concurrent use.
+type Recorder struct {
+	mu      sync.Mutex
+	entries []bayaan.Entry
+}
+
+// NewRecorder returns an empty Recorder. Add it to a logger with
+// bayaan.WithSink.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// NewLogger returns a synchronous logger with options applied, writing only
+// to the returned Recorder, so entries are recorded by the time each log
+// call returns.
+func NewLogger(options ...bayaan.LoggerOption) (*bayaan.Logger, *Recorder) {
+	r := NewRecorder()
+	options = append([]bayaan.LoggerOption{bayaan.WithSync(), bayaan.WithLevel(bayaan.LoggerLevelTrace)}, options...)
+	options = append(options, bayaan.WithSink(r, false))
+	return bayaan.NewLogger(options...), r
+}
+
+// WriteEntry records a copy of e.
+func (r *Recorder) WriteEntry(e *bayaan.Entry) error {
+	entry := *e
+	entry.Fields = make(bayaan.Fields, len(e.Fields))
+	for k, v := range e.Fields {
+		entry.Fields[k] = v
+	}
+	r.mu.Lock()
+	r.entries = append(r.entries, entry)
+	r.mu.Unlock()
+	return nil
+}
+
+// Entries returns the recorded entries, oldest first.
+func (r *Recorder) Entries() []bayaan.Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]bayaan.Entry(nil), r.entries...)
+}
+
+// LastEntry returns the most recent entry, and false if there is none.
+func (r *Recorder) LastEntry() (bayaan.Entry, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.entries) == 0 {
+		return bayaan.Entry{}, false
+	}
+	return r.entries[len(r.entries)-1], true
+}
+
+// Reset discards the recorded entries.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	r.entries = nil
+	r.mu.Unlock()
+}
+
+// Find returns the recorded entries matching level, msgSubstr and fields,
+// as described on AssertLogged.
+func (r *Recorder) Find(level bayaan.LoggerLevel, msgSubstr string, fields bayaan.Fields) []bayaan.Entry {
+	var found []bayaan.Entry
+	for _, e := range r.Entries() {
+		if Match(&e, level, msgSubstr, fields) {
+			found = append(found, e)
+		}
+	}
+	return found
+}
+
+// AssertLogged fails t unless an entry was recorded at level whose message
+// contains msgSubstr and whose fields include fields. It reports whether
+// one was.
+func (r *Recorder) AssertLogged(t testing.TB, level bayaan.LoggerLevel, msgSubstr string, fields bayaan.Fields) bool {
+	t.Helper()
+	if len(r.Find(level, msgSubstr, fields)) > 0 {
+		return true
+	}
+	t.Errorf("no %v entry containing %q with fields %v; recorded:\n%s", level, msgSubstr, fields, r.dump())
+	return false
+}
+
+// AssertNotLogged fails t if an entry matching level, msgSubstr and fields
+// was recorded. It reports whether none was.
+func (r *Recorder) AssertNotLogged(t testing.TB, level bayaan.LoggerLevel, msgSubstr string, fields bayaan.Fields) bool {
+	t.Helper()
+	found := r.Find(level, msgSubstr, fields)
+	if len(found) == 0 {
+		return true
+	}
+	t.Errorf("unexpected %v entry containing %q with fields %v:\n%s", level, msgSubstr, fields, found[0].String())
+	return false
+}
+
+// Match reports whether e is at level, its message contains msgSubstr and
+// it has every field in fields. Field values match when they are deeply
+// equal or print the same, so an expected 42 matches a logged int64(42).
+func Match(e *bayaan.Entry, level bayaan.LoggerLevel, msgSubstr string, fields bayaan.Fields) bool {
+	if e.Level != level || !strings.Contains(e.Message, msgSubstr) {
+		return false
+	}
+	for k, want := range fields {
+		got, ok := e.Fields[k]
+		if !ok {
+			return false
+		}
+		if !reflect.DeepEqual(got, want) && fmt.Sprint(got) != fmt.Sprint(want) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Recorder) dump() string {
+	entries := r.Entries()
+	if len(entries) == 0 {
+		return "  (nothing)"
+	}
+	var b strings.Builder
+	for _, e := range entries {
+		b.WriteString(e.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
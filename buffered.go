@@ -0,0 +1,80 @@
+package bayaan
+
+import (
+	"bufio"
+	"io"
+	"sync"
+	"time"
+)
+
+// BufferedWriter batches writes to an underlying writer, flushing when its
+// buffer fills, every flush interval, and on Flush or Close. It trades a
+// bounded delay (and, on a crash, the unflushed tail) for far fewer
+// syscalls.
+type BufferedWriter struct {
+	mu   sync.Mutex
+	buf  *bufio.Writer
+	once sync.Once
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewBufferedWriter wraps w with a size-byte buffer flushed at least every
+// flushInterval. A zero interval only flushes when the buffer fills or on
+// Flush and Close.
+func NewBufferedWriter(w io.Writer, size int, flushInterval time.Duration) *BufferedWriter {
+	b := &BufferedWriter{
+		buf:  bufio.NewWriterSize(w, size),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	if flushInterval <= 0 {
+		close(b.done)
+		return b
+	}
+	go func() {
+		defer close(b.done)
+		ticker := time.NewTicker(flushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				b.Flush()
+			case <-b.stop:
+				return
+			}
+		}
+	}()
+	return b
+}
+
+func (b *BufferedWriter) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (b *BufferedWriter) Flush() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Flush()
+}
+
+// Close stops the periodic flush and flushes what is left. The underlying
+// writer is not closed.
+func (b *BufferedWriter) Close() error {
+	b.once.Do(func() { close(b.stop) })
+	<-b.done
+	return b.Flush()
+}
+
+// WithBufferedOutput adds an uncolored output writing to w through a
+// BufferedWriter of size bytes, flushed every flushInterval and when the
+// logger is flushed or closed.
+func WithBufferedOutput(w io.Writer, size int, flushInterval time.Duration) LoggerOption {
+	return func(l *Logger) {
+		b := NewBufferedWriter(w, size, flushInterval)
+		l.addOutput(output{writer: b, closer: b}, true)
+	}
+}
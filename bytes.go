@@ -0,0 +1,47 @@
+package bayaan
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+type BytesFormat int
+
+const (
+	BytesHex BytesFormat = iota
+	BytesBase64
+	// BytesSummary renders only the length and a short SHA-256 prefix.
+	BytesSummary
+)
+
+type bytesEncoding struct {
+	format    BytesFormat
+	threshold int
+}
+
+// defaultBytesEncoding keeps small payloads readable and large ones out.
+var defaultBytesEncoding = bytesEncoding{format: BytesHex, threshold: 256}
+
+func (b bytesEncoding) render(p []byte) string {
+	if b.format == BytesSummary || (b.threshold > 0 && len(p) > b.threshold) {
+		sum := sha256.Sum256(p)
+		return fmt.Sprintf("<%d bytes sha256:%x>", len(p), sum[:8])
+	}
+	if b.format == BytesBase64 {
+		return base64.StdEncoding.EncodeToString(p)
+	}
+	return hex.EncodeToString(p)
+}
+
+// WithBytesFormat sets how []byte field values are rendered. Values longer
+// than threshold bytes are summarized as their length and hash instead; a
+// threshold of 0 never summarizes. The default is hex up to 256 bytes.
+func WithBytesFormat(format BytesFormat, threshold int) LoggerOption {
+	return func(l *Logger) {
+		l.mu.Lock()
+		l.bytesEnc = bytesEncoding{format: format, threshold: threshold}
+		l.mu.Unlock()
+	}
+}
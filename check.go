@@ -0,0 +1,58 @@
+package bayaan
+
+// Enabled reports whether an entry at level would be logged, so callers
+// can skip building costly fields. TRACE and DEBUG count as enabled while
+// WithRingBuffer keeps them. Only the level is consulted; sampling and
+// filters may still drop the entry.
+func (l *Logger) Enabled(level LoggerLevel) bool {
+	return l.enabled(level)
+}
+
+// CheckedEntry is an entry that passed the level check and waits for its
+// fields. See Logger.Check.
+type CheckedEntry struct {
+	logger *Logger
+	level  LoggerLevel
+	msg    string
+}
+
+// Check returns an entry at level to complete with Write, or nil when the
+// level is disabled:
+//
+//	if ce := logger.Check(bayaan.LoggerLevelDebug, "cache state"); ce != nil {
+//		ce.Write(bayaan.Any("entries", cache.Snapshot()))
+//	}
+//
+// Writing a FATAL or PANIC entry exits or panics as Fatal and Panic do,
+// so for those levels Check never returns nil.
+func (l *Logger) Check(level LoggerLevel, msg string) *CheckedEntry {
+	if level != LoggerLevelFatal && level != LoggerLevelPanic && !l.enabled(level) {
+		return nil
+	}
+	return &CheckedEntry{logger: l, level: level, msg: msg}
+}
+
+// Write logs the entry with fields. It does nothing on a nil entry, so the
+// result of Check can be written without a nil check when the fields are
+// cheap.
+func (c *CheckedEntry) Write(fields ...Field) {
+	if c == nil {
+		return
+	}
+	l := c.logger
+	l.emit(logEntry{level: c.level, msg: c.msg, typed: fields, logger: l})
+	switch c.level {
+	case LoggerLevelFatal:
+		l.terminate(fieldsOf(fields))
+	case LoggerLevelPanic:
+		l.panic(c.msg)
+	}
+}
+
+func Enabled(level LoggerLevel) bool {
+	return defaultLogger.Enabled(level)
+}
+
+func Check(level LoggerLevel, msg string) *CheckedEntry {
+	return defaultLogger.Check(level, msg)
+}
@@ -0,0 +1,118 @@
+package bayaan
+
+// Clone returns an independent logger, with its own queue and writer,
+// configured like l and then by options:
+//
+//	audit := logger.Clone(bayaan.WithOutputsReplaced(
+//		bayaan.WithRotatingFile("/var/log/app/audit.log", 100, 0, 30)))
+//
+// The clone starts with l's level, fields, format, hooks and outputs.
+// Most output options add to the outputs, so without WithOutputsReplaced
+// the clone writes to l's outputs too. Those are shared: both loggers
+// write to the same files and sinks, and closing the clone leaves them
+// open. Hooks are shared the same way and closed by l, so close the clone
+// first; once l is closed, hooks such as SentryHook reject the clone's
+// entries with an error. Sampling, deduplication and the ring buffer
+// start afresh in the clone, while rate limits and background features
+// such as WithFlushOnSignal or WithRuntimeStats belong to l and must be
+// given again as options.
+func (l *Logger) Clone(options ...LoggerOption) *Logger {
+	return NewLogger(append([]LoggerOption{l.seed}, options...)...)
+}
+
+// seed copies the configuration of l into c, a logger being built by
+// NewLogger.
+func (l *Logger) seed(c *Logger) {
+	level := l.minLevel()
+	fields := l.Fields()
+
+	// Derived loggers write through the logger NewLogger built, which holds
+	// the current outputs and hooks.
+	owner := l.state.owner
+	owner.mu.RLock()
+	defer owner.mu.RUnlock()
+
+	healths := make(map[*outputHealth]*outputHealth, len(owner.outputs))
+	outputs := make([]output, len(owner.outputs))
+	for i, out := range owner.outputs {
+		h := &outputHealth{}
+		healths[out.health] = h
+		out.health = h
+		out.closer = nil // closed by the owner
+		out.index = nil  // offsets in the index only hold for one writer
+		outputs[i] = out
+	}
+	for i, out := range outputs {
+		if out.failover != nil {
+			f := &failover{ahead: make([]*outputHealth, len(out.failover.ahead))}
+			for j, h := range out.failover.ahead {
+				f.ahead[j] = healths[h]
+			}
+			outputs[i].failover = f
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.level.store(level)
+	c.outputs = outputs
+	c.fields = fields
+	c.timeFormat = owner.timeFormat
+	c.location = owner.location
+	c.formatter = owner.formatter
+	c.bytesEnc = owner.bytesEnc
+	c.limits = owner.limits
+	c.filters = append(([]func(Entry) bool)(nil), owner.filters...)
+	c.hooks = append([]Hook(nil), owner.hooks...)
+	c.borrowed = len(c.hooks)
+	c.redact = owner.redact
+	c.exitCodes = owner.exitCodes
+	c.exitFunc = owner.exitFunc
+	c.onFatal = append(([]func(int))(nil), owner.onFatal...)
+	c.fatal = owner.fatal
+	c.timeout = owner.timeout
+	c.color = owner.color
+	c.theme = owner.theme
+	c.tracer = owner.tracer
+	c.clock = owner.clock
+	c.ids = owner.ids
+	c.entryIDs = owner.entryIDs
+	c.diag = owner.diag
+	c.onDrop = owner.onDrop
+	c.onError = owner.onError
+	c.writers = owner.writers
+	c.logChan = make(chan logEntry, cap(owner.logChan))
+	c.state.inline = owner.state.inline
+
+	if owner.samplers != nil {
+		c.samplers = make(map[LoggerLevel]*sampler, len(owner.samplers))
+		for level, s := range owner.samplers {
+			c.samplers[level] = &sampler{tick: s.tick, first: s.first, thereafter: s.thereafter}
+		}
+	}
+	if ms := owner.msgSampler; ms != nil {
+		c.msgSampler = &messageSampler{tick: ms.tick, first: ms.first, thereafter: ms.thereafter}
+	}
+	if owner.dedup != nil {
+		c.dedup = &dedup{window: owner.dedup.window}
+	}
+	if owner.ring != nil {
+		c.ring = &ring{entries: make([]logEntry, len(owner.ring.entries))}
+	}
+}
+
+// WithOutputsReplaced removes the outputs configured so far, closing
+// those the logger opened itself, and adds those of options instead. It
+// is how a Clone writes elsewhere than the logger it came from.
+func WithOutputsReplaced(options ...LoggerOption) LoggerOption {
+	return func(l *Logger) {
+		withoutOutputs()(l)
+		for _, option := range options {
+			option(l)
+		}
+	}
+}
+
+func Clone(options ...LoggerOption) *Logger {
+	return defaultLogger.Clone(options...)
+}
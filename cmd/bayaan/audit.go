@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ahmedsat/bayaan"
+)
+
+func verifyAudit(args []string) error {
+	fs := flag.NewFlagSet("verify-audit", flag.ExitOnError)
+	keyFile := fs.String("key-file", "", "file holding the HMAC key the log was written with")
+	head := fs.String("head", "", "expected hash of the last record, to detect truncation")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		return fmt.Errorf("verify-audit: no files given")
+	}
+	var key []byte
+	if *keyFile != "" {
+		k, err := os.ReadFile(*keyFile)
+		if err != nil {
+			return err
+		}
+		key = []byte(strings.TrimRight(string(k), "\r\n"))
+	}
+
+	for _, path := range fs.Args() {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		last, err := bayaan.VerifyAudit(f, key)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if *head != "" && last != *head {
+			return fmt.Errorf("%s: chain ends at %s, expected %s", path, last, *head)
+		}
+		fmt.Printf("%s: ok, head %s\n", path, last)
+	}
+	return nil
+}
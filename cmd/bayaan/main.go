@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ahmedsat/bayaan"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: bayaan <command> [flags] files...\n\ncommands:\n  extract  print entries within a time window\n  ship     push stored entries to a sink\n  verify-audit  check the hash chain of audit logs\n")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "extract":
+		err = extract(os.Args[2:])
+	case "ship":
+		err = ship(os.Args[2:])
+	case "verify-audit":
+		err = verifyAudit(os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "bayaan: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation(bayaan.DefaultTimeFormat, s, time.Local)
+}
+
+func extract(args []string) error {
+	fs := flag.NewFlagSet("extract", flag.ExitOnError)
+	fromFlag := fs.String("from", "", "start of the window (RFC 3339 or \""+bayaan.DefaultTimeFormat+"\")")
+	toFlag := fs.String("to", "", "end of the window")
+	levelFlag := fs.String("level", "", "minimum level to include")
+	grepFlag := fs.String("grep", "", "only include entries whose message contains this text")
+	fs.Parse(args)
+
+	from, err := parseTime(*fromFlag)
+	if err != nil {
+		return err
+	}
+	to, err := parseTime(*toFlag)
+	if err != nil {
+		return err
+	}
+	minLevel := bayaan.LoggerLevelTrace
+	if *levelFlag != "" {
+		if minLevel, err = bayaan.ParseLevel(*levelFlag); err != nil {
+			return err
+		}
+	}
+
+	entries, err := bayaan.Extract(fs.Args(), from, to, func(e *bayaan.Entry) bool {
+		return e.Level.AtLeast(minLevel) && strings.Contains(e.Message, *grepFlag)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		fmt.Println(e)
+	}
+	return nil
+}
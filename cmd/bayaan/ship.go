@@ -0,0 +1,114 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/ahmedsat/bayaan"
+)
+
+type listFlag []string
+
+func (f *listFlag) String() string { return strings.Join(*f, ",") }
+
+func (f *listFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+// openSink resolves a --to destination:
+//
+//	loki://host:3100         Loki push API over http
+//	loki+https://host        Loki push API over https
+//	file:///path, /path, -   bayaan text format to a file or stdout
+func openSink(to string, labels map[string]string) (bayaan.LoggerOption, func() error, error) {
+	if to == "-" {
+		return bayaan.WithOutput(os.Stdout, false, false), func() error { return nil }, nil
+	}
+
+	u, err := url.Parse(to)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch u.Scheme {
+	case "loki", "loki+http", "loki+https":
+		scheme := strings.TrimPrefix(strings.TrimPrefix(u.Scheme, "loki"), "+")
+		if scheme == "" {
+			scheme = "http"
+		}
+		path := u.Path
+		if path == "" || path == "/" {
+			path = "/loki/api/v1/push"
+		}
+		sink := bayaan.NewLokiSink(scheme+"://"+u.Host+path, labels)
+		return bayaan.WithSink(sink, false), sink.Flush, nil
+	case "file", "":
+		path := u.Path
+		if u.Scheme == "" {
+			path = to
+		}
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, nil, err
+		}
+		return bayaan.WithOutput(f, false, false), f.Close, nil
+	}
+	return nil, nil, fmt.Errorf("unsupported destination %q", to)
+}
+
+func ship(args []string) error {
+	fs := flag.NewFlagSet("ship", flag.ExitOnError)
+	var from, labelFlags listFlag
+	fs.Var(&from, "from", "log file to read, plain or .gz (repeatable)")
+	to := fs.String("to", "", "destination, e.g. loki://localhost:3100")
+	fs.Var(&labelFlags, "label", "stream label as key=value (repeatable)")
+	sinceFlag := fs.String("since", "", "only ship entries at or after this time")
+	untilFlag := fs.String("until", "", "only ship entries at or before this time")
+	fs.Parse(args)
+
+	from = append(from, fs.Args()...)
+	if len(from) == 0 || *to == "" {
+		return fmt.Errorf("ship: --from and --to are required")
+	}
+
+	labels := map[string]string{"job": "bayaan-ship"}
+	for _, l := range labelFlags {
+		k, v, ok := strings.Cut(l, "=")
+		if !ok {
+			return fmt.Errorf("ship: bad label %q", l)
+		}
+		labels[k] = v
+	}
+
+	since, err := parseTime(*sinceFlag)
+	if err != nil {
+		return err
+	}
+	until, err := parseTime(*untilFlag)
+	if err != nil {
+		return err
+	}
+	entries, err := bayaan.Extract(from, since, until, nil)
+	if err != nil {
+		return err
+	}
+
+	option, flush, err := openSink(*to, labels)
+	if err != nil {
+		return err
+	}
+	logger := bayaan.NewLogger(bayaan.WithLevel(bayaan.LoggerLevelTrace), option)
+	for _, e := range entries {
+		logger.Replay(e)
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	logger.Close()
+
+	fmt.Fprintf(os.Stderr, "shipped %d entries\n", len(entries))
+	return nil
+}
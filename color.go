@@ -0,0 +1,124 @@
+package bayaan
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strconv"
+)
+
+// ColorMode decides whether outputs added with colors enabled actually
+// write ANSI color codes.
+type ColorMode int
+
+const (
+	// ColorAuto colors only outputs writing to a terminal, and none when
+	// the NO_COLOR environment variable is set (https://no-color.org).
+	// Files, pipes and container log collectors get plain text.
+	ColorAuto ColorMode = iota
+	// ColorAlways colors every output added with colors enabled.
+	ColorAlways
+	// ColorNever disables colors everywhere.
+	ColorNever
+)
+
+// WithColorMode overrides terminal and NO_COLOR detection. The default is
+// ColorAuto. Outputs added with colors disabled are never colored.
+func WithColorMode(mode ColorMode) LoggerOption {
+	return func(l *Logger) {
+		l.mu.Lock()
+		l.color = mode
+		l.mu.Unlock()
+	}
+}
+
+func (m ColorMode) colorize(out output) bool {
+	switch m {
+	case ColorAlways:
+		return out.useColor
+	case ColorNever:
+		return false
+	}
+	return out.terminal
+}
+
+// colorTerminal reports whether w is a terminal and NO_COLOR is unset.
+func colorTerminal(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Theme chooses how colored outputs highlight each level.
+type Theme struct {
+	// Levels maps levels to ANSI escape sequences, such as those returned
+	// by Color256 and TrueColor. Levels missing from the map keep their
+	// default color; an empty sequence leaves the level uncolored.
+	Levels map[LoggerLevel]string
+	// LevelOnly colors just the level name, the first occurrence of it in
+	// the line, instead of the whole entry. It is meant for the text format.
+	LevelOnly bool
+}
+
+// WithTheme sets the colors used by colored outputs.
+func WithTheme(theme Theme) LoggerOption {
+	levels := make(map[LoggerLevel]string, len(theme.Levels))
+	for level, code := range theme.Levels {
+		levels[level] = code
+	}
+	theme.Levels = levels
+	return func(l *Logger) {
+		l.mu.Lock()
+		l.theme = &theme
+		l.mu.Unlock()
+	}
+}
+
+// WithColors overrides the color of the given levels, coloring whole
+// entries. It is WithTheme(Theme{Levels: colors}).
+func WithColors(colors map[LoggerLevel]string) LoggerOption {
+	return WithTheme(Theme{Levels: colors})
+}
+
+// Color256 returns the escape sequence selecting foreground color n of the
+// 256-color palette.
+func Color256(n uint8) string {
+	return "\033[38;5;" + strconv.Itoa(int(n)) + "m"
+}
+
+// TrueColor returns the escape sequence selecting a 24-bit foreground
+// color, for terminals that support it.
+func TrueColor(r, g, b uint8) string {
+	return "\033[38;2;" + strconv.Itoa(int(r)) + ";" + strconv.Itoa(int(g)) + ";" + strconv.Itoa(int(b)) + "m"
+}
+
+// paint appends line to dst, colored for level. A nil theme uses the
+// default colors.
+func (t *Theme) paint(dst []byte, level LoggerLevel, line []byte) []byte {
+	code := level.color()
+	levelOnly := false
+	if t != nil {
+		if c, ok := t.Levels[level]; ok {
+			code = c
+		}
+		levelOnly = t.LevelOnly
+	}
+	if code == "" {
+		return append(dst, line...)
+	}
+	if levelOnly {
+		name := level.String()
+		if i := bytes.Index(line, []byte(name)); i >= 0 {
+			dst = append(dst, line[:i]...)
+			dst = append(append(append(dst, code...), name...), Reset...)
+			return append(dst, line[i+len(name):]...)
+		}
+	}
+	return append(append(append(dst, code...), line...), Reset...)
+}
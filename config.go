@@ -0,0 +1,265 @@
+package bayaan
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Config declares a logger. In JSON:
+//
+//	{
+//		"level": "info",
+//		"format": "text",
+//		"fields": {"service": "billing"},
+//		"sampling": [{"level": "debug", "first": 100, "thereafter": 10}],
+//		"outputs": [
+//			{"type": "stdout", "color": true},
+//			{"type": "file", "path": "/var/log/billing.log", "format": "json",
+//			 "rotate": {"max_size_mb": 100, "max_age": "168h", "max_backups": 7, "compress": true}}
+//		]
+//	}
+//
+// The same document can be written in YAML. Empty settings keep the
+// logger's defaults.
+type Config struct {
+	Level      string           `json:"level"`
+	Format     string           `json:"format"`      // text, compact, json, ecs or gcp
+	TimeFormat string           `json:"time_format"` // a time layout or unix, unixmilli, unixmicro, unixnano
+	Fields     Fields           `json:"fields"`
+	Sampling   []SamplingConfig `json:"sampling"`
+	Outputs    []OutputConfig   `json:"outputs"` // replace the default stdout output when set
+}
+
+// SamplingConfig configures WithSampling for one level.
+type SamplingConfig struct {
+	Level      string `json:"level"`
+	First      int    `json:"first"`
+	Thereafter int    `json:"thereafter"`
+}
+
+// OutputConfig declares one output.
+type OutputConfig struct {
+	// Type is stdout, stderr, file, syslog, journald, tcp or udp.
+	Type string `json:"type"`
+	// Path is the file written by file outputs.
+	Path string `json:"path"`
+	// Address is the host:port of tcp, udp and remote syslog outputs.
+	Address string `json:"address"`
+	// Format overrides the logger-wide format for this output.
+	Format string `json:"format"`
+	// TimeFormat overrides the logger-wide time format for this output.
+	TimeFormat string `json:"time_format"`
+	// Color enables level colors on stdout and stderr.
+	Color bool `json:"color"`
+	// Rotate makes a file output rotate.
+	Rotate *RotateConfig `json:"rotate"`
+	// MinLevel and MaxLevel limit the output to entries in that range,
+	// bounds included; either may be left out.
+	MinLevel string `json:"min_level"`
+	MaxLevel string `json:"max_level"`
+}
+
+// RotateConfig configures WithRotatingFile.
+type RotateConfig struct {
+	MaxSizeMB  int    `json:"max_size_mb"`
+	MaxAge     string `json:"max_age"` // a time.ParseDuration string
+	MaxBackups int    `json:"max_backups"`
+	Compress   bool   `json:"compress"`
+}
+
+// ConfigFromFile reads a Config from path and returns its options. Files
+// ending in .yaml or .yml are read as YAML, anything else as JSON. Unknown
+// settings are rejected, so typos do not go unnoticed.
+//
+// The YAML reader covers block mappings and sequences, comments, quoted
+// and plain scalars and single-line flow sequences, which is what logging
+// configs use; anchors, multi-line strings and multiple documents are not
+// supported.
+func ConfigFromFile(path string) ([]LoggerOption, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		v, err := parseYAML(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if data, err = json.Marshal(v); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	var cfg Config
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	options, err := cfg.Options()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return options, nil
+}
+
+// Options returns the logger options c declares. Plain file outputs are
+// opened here, so errors such as missing directories surface before the
+// logger is built.
+func (c *Config) Options() ([]LoggerOption, error) {
+	var options []LoggerOption
+
+	if c.Level != "" {
+		level, err := ParseLevel(c.Level)
+		if err != nil {
+			return nil, fmt.Errorf("level: %w", err)
+		}
+		options = append(options, WithLevel(level))
+	}
+	if c.TimeFormat != "" {
+		options = append(options, WithTimeFormat(c.TimeFormat))
+	}
+	if c.Format != "" {
+		f, err := formatterFor(c.Format, c.TimeFormat)
+		if err != nil {
+			return nil, fmt.Errorf("format: %w", err)
+		}
+		options = append(options, WithFormatter(f))
+	}
+	if len(c.Fields) > 0 {
+		options = append(options, WithFields(c.Fields))
+	}
+
+	for i, s := range c.Sampling {
+		level, err := ParseLevel(s.Level)
+		if err != nil {
+			return nil, fmt.Errorf("sampling[%d]: %w", i, err)
+		}
+		options = append(options, WithSampling(level, s.First, s.Thereafter))
+	}
+
+	if len(c.Outputs) > 0 {
+		options = append(options, withoutOutputs())
+	}
+	for i, o := range c.Outputs {
+		option, err := c.output(o)
+		if err != nil {
+			return nil, fmt.Errorf("outputs[%d]: %w", i, err)
+		}
+		options = append(options, option)
+	}
+	return options, nil
+}
+
+// formatterFor returns the Formatter for a format name and time format.
+// nil means the logger's default text format.
+func formatterFor(name, timeFormat string) (Formatter, error) {
+	switch strings.ToLower(name) {
+	case "text":
+		if timeFormat == "" {
+			return nil, nil
+		}
+		return &TextFormatter{TimeFormat: timeFormat}, nil
+	case "compact":
+		return &TextFormatter{TimeFormat: timeFormat, Compact: true}, nil
+	case "json":
+		return &JSONFormatter{TimeFormat: timeFormat}, nil
+	case "ecs":
+		return &ECSFormatter{}, nil
+	case "gcp":
+		return &GCPFormatter{}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q", name)
+}
+
+func (c *Config) output(o OutputConfig) (LoggerOption, error) {
+	var option LoggerOption
+	switch strings.ToLower(o.Type) {
+	case "stdout":
+		option = WithOutput(os.Stdout, true, o.Color)
+	case "stderr":
+		option = WithOutput(os.Stderr, true, o.Color)
+	case "syslog":
+		network := ""
+		if o.Address != "" {
+			network = "udp"
+		}
+		option = WithSyslogOutput(network, o.Address)
+	case "journald":
+		option = WithJournaldOutput(filepath.Base(os.Args[0]))
+	case "tcp", "udp":
+		if o.Address == "" {
+			return nil, fmt.Errorf("%s output without an address", o.Type)
+		}
+		option = WithNetworkOutput(strings.ToLower(o.Type), o.Address)
+	case "file":
+		if o.Path == "" {
+			return nil, fmt.Errorf("file output without a path")
+		}
+		if r := o.Rotate; r != nil {
+			var maxAge time.Duration
+			if r.MaxAge != "" {
+				d, err := time.ParseDuration(r.MaxAge)
+				if err != nil {
+					return nil, fmt.Errorf("rotate.max_age: %w", err)
+				}
+				maxAge = d
+			}
+			var rotate []RotateOption
+			if r.Compress {
+				rotate = append(rotate, WithCompression())
+			}
+			option = WithRotatingFile(o.Path, r.MaxSizeMB, maxAge, r.MaxBackups, rotate...)
+			break
+		}
+		var err error
+		if option, err = fileOutput(o.Path); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown output type %q", o.Type)
+	}
+
+	if o.Format != "" || o.TimeFormat != "" {
+		format, timeFormat := o.Format, o.TimeFormat
+		if format == "" {
+			format = c.Format
+		}
+		if format == "" {
+			format = "text"
+		}
+		if timeFormat == "" {
+			timeFormat = c.TimeFormat
+		}
+		f, err := formatterFor(format, timeFormat)
+		if err != nil {
+			return nil, err
+		}
+		if f == nil {
+			f = &TextFormatter{}
+		}
+		option = WithOutputFormatter(f, option)
+	}
+
+	if o.MinLevel != "" {
+		min, err := ParseLevel(o.MinLevel)
+		if err != nil {
+			return nil, fmt.Errorf("min_level: %w", err)
+		}
+		option = WithOutputLevel(min, option)
+	}
+	if o.MaxLevel != "" {
+		max, err := ParseLevel(o.MaxLevel)
+		if err != nil {
+			return nil, fmt.Errorf("max_level: %w", err)
+		}
+		option = withLevels(max.AtLeast, option)
+	}
+	return option, nil
+}
@@ -0,0 +1,206 @@
+// Package conformance checks Formatter implementations against the
+// guarantees bayaan's outputs rely on, using adversarial entries: huge
+// messages and fields, NaN and infinite floats, self-referencing values,
+// invalid UTF-8 and typed nil errors.
+//
+// Call it from a test or fuzz target in the formatter's own package:
+//
+//	func TestConformance(t *testing.T) {
+//		conformance.Run(t, &MyFormatter{}, conformance.SingleLine)
+//	}
+//
+//	func FuzzConformance(f *testing.F) {
+//		conformance.Fuzz(f, &MyFormatter{}, conformance.SingleLine)
+//	}
+package conformance
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahmedsat/bayaan"
+)
+
+// Check is an additional guarantee a formatter makes, verified against the
+// output produced for every entry.
+type Check func(e *bayaan.Entry, out []byte) error
+
+// SingleLine requires the output to contain no line breaks.
+func SingleLine(e *bayaan.Entry, out []byte) error {
+	if i := bytes.IndexAny(out, "\r\n"); i >= 0 {
+		return fmt.Errorf("line break at offset %d", i)
+	}
+	return nil
+}
+
+// ValidJSON requires the output to be a single valid JSON object.
+func ValidJSON(e *bayaan.Entry, out []byte) error {
+	var v map[string]interface{}
+	if err := json.Unmarshal(out, &v); err != nil {
+		return fmt.Errorf("invalid JSON: %v", err)
+	}
+	return nil
+}
+
+// Case is a named adversarial entry.
+type Case struct {
+	Name  string
+	Entry *bayaan.Entry
+}
+
+type node struct {
+	Name string
+	Next *node
+}
+
+type nilDerefError struct{ msg string }
+
+func (e *nilDerefError) Error() string { return e.msg }
+
+// Cases returns the entries Run formats. Each call builds fresh values.
+func Cases() []Case {
+	at := time.Date(2024, 2, 29, 23, 59, 59, 999999999, time.UTC)
+	entry := func(msg string, fields bayaan.Fields) *bayaan.Entry {
+		return &bayaan.Entry{Level: bayaan.LoggerLevelInfo, Time: at, Message: msg, Fields: fields}
+	}
+
+	cyclicMap := map[string]interface{}{"name": "loop"}
+	cyclicMap["self"] = cyclicMap
+	cyclicSlice := make([]interface{}, 2)
+	cyclicSlice[0] = "loop"
+	cyclicSlice[1] = cyclicSlice
+	cyclicGroup := bayaan.Fields{"name": "loop"}
+	cyclicGroup["self"] = cyclicGroup
+	ring := &node{Name: "a"}
+	ring.Next = &node{Name: "b", Next: ring}
+
+	many := make(bayaan.Fields, 10000)
+	for i := 0; i < 10000; i++ {
+		many[fmt.Sprintf("field_%d", i)] = i
+	}
+
+	var nilErr *nilDerefError
+	return []Case{
+		{"empty", &bayaan.Entry{}},
+		{"zero time with fields", &bayaan.Entry{Level: bayaan.LoggerLevelError, Fields: bayaan.Fields{"k": "v"}}},
+		{"huge message", entry(strings.Repeat("m", 1<<20), nil)},
+		{"huge field", entry("huge field", bayaan.Fields{"blob": strings.Repeat("x", 1<<20)})},
+		{"many fields", entry("many fields", many)},
+		{"floats", entry("floats", bayaan.Fields{
+			"nan": math.NaN(), "inf": math.Inf(1), "-inf": math.Inf(-1),
+			"nan32": float32(math.NaN()), "max": math.MaxFloat64, "tiny": math.SmallestNonzeroFloat64,
+		})},
+		{"integers", entry("integers", bayaan.Fields{
+			"min": int64(math.MinInt64), "max": uint64(math.MaxUint64), "int8": int8(-128),
+		})},
+		{"invalid utf-8", entry("bad \xff\xfe bytes", bayaan.Fields{"k\xc3\x28": "v\xe2\x28\xa1"})},
+		{"control characters", entry("line1\nline2\r\x00\x1b[31mred\x1b[0m\t", bayaan.Fields{"ctl": "a\nb\x7f"})},
+		{"reserved keys", entry("reserved keys", bayaan.Fields{"time": "t", "level": "l", "msg": "m", "": "empty key"})},
+		{"cyclic map", entry("cyclic map", bayaan.Fields{"m": cyclicMap})},
+		{"cyclic slice", entry("cyclic slice", bayaan.Fields{"s": cyclicSlice})},
+		{"groups", entry("groups", bayaan.Fields{
+			"http":  bayaan.Fields{"method": "GET", "status": 200, "req": bayaan.Fields{"nan": math.NaN()}},
+			"empty": bayaan.Fields{}, "nil": bayaan.Fields(nil),
+		})},
+		{"cyclic group", entry("cyclic group", bayaan.Fields{"g": cyclicGroup})},
+		{"cyclic pointers", entry("cyclic pointers", bayaan.Fields{"ring": ring})},
+		{"nils", entry("nils", bayaan.Fields{
+			"nil": nil, "map": map[string]int(nil), "ptr": (*node)(nil), "err": error(nilErr),
+		})},
+		{"values", entry("values", bayaan.Fields{
+			"err": errors.New("boom"), "dur": 1500 * time.Millisecond, "time": at,
+			"bytes": []byte{0, 1, 2}, "struct": struct{ A, b int }{1, 2}, "func": func() {},
+			"chan": make(chan int), "complex": complex(1, -1),
+		})},
+		{"unknown level", &bayaan.Entry{Level: bayaan.LoggerLevel(99), Time: at, Message: "unknown level"}},
+	}
+}
+
+// Run formats every entry from Cases with f, as a subtest per case. A
+// subtest fails if Format panics, modifies the entry, or succeeds with
+// empty output, output ending in a newline, or output violating any of
+// checks. Formatters may return an error instead of rendering an entry.
+func Run(t *testing.T, f bayaan.Formatter, checks ...Check) {
+	t.Helper()
+	for _, c := range Cases() {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			if err := Verify(f, c.Entry, checks...); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// Fuzz fuzzes f with entries built from an arbitrary level, message, field
+// key and values, applying the same guarantees as Run.
+func Fuzz(f *testing.F, formatter bayaan.Formatter, checks ...Check) {
+	f.Helper()
+	f.Add(int(bayaan.LoggerLevelInfo), "hello", "key", "value", int64(42), 3.14)
+	f.Add(int(bayaan.LoggerLevelError), "bad \xff", "k\n", "\x00\x1b[0m", int64(math.MinInt64), math.NaN())
+	f.Add(-1, "", "", "", int64(0), math.Inf(-1))
+	f.Fuzz(func(t *testing.T, level int, msg, key, str string, n int64, x float64) {
+		e := &bayaan.Entry{
+			Level:   bayaan.LoggerLevel(level),
+			Time:    time.Unix(0, n).UTC(),
+			Message: msg,
+			Fields:  bayaan.Fields{key: str, key + "_int": n, key + "_float": x},
+		}
+		if err := Verify(formatter, e, checks...); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+// Verify formats e with f and reports the first violated guarantee.
+func Verify(f bayaan.Formatter, e *bayaan.Entry, checks ...Check) (err error) {
+	level, msg, at := e.Level, e.Message, e.Time
+	keys := make(map[string]bool, len(e.Fields))
+	for k := range e.Fields {
+		keys[k] = true
+	}
+
+	var out []byte
+	var ferr error
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("Format panicked: %v", r)
+			}
+		}()
+		out, ferr = f.Format(e)
+	}()
+	if err != nil {
+		return err
+	}
+
+	if e.Level != level || e.Message != msg || !e.Time.Equal(at) || len(e.Fields) != len(keys) {
+		return errors.New("Format modified the entry")
+	}
+	for k := range e.Fields {
+		if !keys[k] {
+			return fmt.Errorf("Format added field %q", k)
+		}
+	}
+	if ferr != nil {
+		return nil
+	}
+	if len(out) == 0 {
+		return errors.New("Format returned empty output")
+	}
+	if out[len(out)-1] == '\n' {
+		return errors.New("output ends with a newline")
+	}
+	for _, check := range checks {
+		if err := check(e, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package bayaan_test
+
+import (
+	"testing"
+
+	"github.com/ahmedsat/bayaan"
+	"github.com/ahmedsat/bayaan/conformance"
+)
+
+func TestConformance(t *testing.T) {
+	formatters := []struct {
+		name      string
+		formatter bayaan.Formatter
+		checks    []conformance.Check
+	}{
+		{"text", &bayaan.TextFormatter{}, nil},
+		{"compact", &bayaan.TextFormatter{Compact: true}, []conformance.Check{conformance.SingleLine}},
+		{"json", &bayaan.JSONFormatter{}, []conformance.Check{conformance.SingleLine, conformance.ValidJSON}},
+		{"json lowercase", &bayaan.JSONFormatter{LevelEncoding: bayaan.LevelLowercase}, []conformance.Check{conformance.SingleLine, conformance.ValidJSON}},
+		{"ecs", &bayaan.ECSFormatter{}, []conformance.Check{conformance.SingleLine, conformance.ValidJSON}},
+		{"gcp", &bayaan.GCPFormatter{ProjectID: "project"}, []conformance.Check{conformance.SingleLine, conformance.ValidJSON}},
+	}
+	for _, tt := range formatters {
+		t.Run(tt.name, func(t *testing.T) {
+			conformance.Run(t, tt.formatter, tt.checks...)
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package bayaan
+
+import "context"
+
+// TraceExtractor returns the IDs of the span active in ctx, or empty
+// strings when there is none. With OpenTelemetry:
+//
+//	func(ctx context.Context) (string, string) {
+//		sc := trace.SpanContextFromContext(ctx)
+//		if !sc.IsValid() {
+//			return "", ""
+//		}
+//		return sc.TraceID().String(), sc.SpanID().String()
+//	}
+type TraceExtractor func(ctx context.Context) (traceID, spanID string)
+
+// WithTraceExtractor makes the *Ctx methods add "trace_id" and "span_id"
+// fields from the context. A request ID stored with ContextWithRequestID is
+// added as "request_id" without one.
+func WithTraceExtractor(extract TraceExtractor) LoggerOption {
+	return func(l *Logger) {
+		l.mu.Lock()
+		l.tracer = extract
+		l.mu.Unlock()
+	}
+}
+
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying id as its request,
+// or correlation, ID. The *Ctx methods add it to entries as "request_id".
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID ctx carries, or "" if it has none.
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// ContextWithNewRequestID returns a copy of ctx carrying a fresh request ID
+// from the logger's NewID, and the ID, e.g. for a job picked off a queue.
+func (l *Logger) ContextWithNewRequestID(ctx context.Context) (context.Context, string) {
+	id := l.NewID()
+	return ContextWithRequestID(ctx, id), id
+}
+
+// ContextWithNewRequestID is Logger.ContextWithNewRequestID for the
+// default logger.
+func ContextWithNewRequestID(ctx context.Context) (context.Context, string) {
+	return defaultLogger.ContextWithNewRequestID(ctx)
+}
+
+// contextFields returns fields extended with what the context carries,
+// without modifying the caller's map.
+func (l *Logger) contextFields(ctx context.Context, fields Fields) Fields {
+	if ctx == nil {
+		return fields
+	}
+	l.mu.RLock()
+	extract := l.tracer
+	l.mu.RUnlock()
+
+	var traceID, spanID string
+	if extract != nil {
+		traceID, spanID = extract(ctx)
+	}
+	requestID := RequestID(ctx)
+	if traceID == "" && spanID == "" && requestID == "" {
+		return fields
+	}
+	out := make(Fields, len(fields)+3)
+	for k, v := range fields {
+		out[k] = v
+	}
+	if requestID != "" {
+		out["request_id"] = requestID
+	}
+	if traceID != "" {
+		out["trace_id"] = traceID
+	}
+	if spanID != "" {
+		out["span_id"] = spanID
+	}
+	return out
+}
+
+func (l *Logger) TraceCtx(ctx context.Context, msg string, fields Fields) {
+	if l.enabled(LoggerLevelTrace) {
+		l.Trace(msg, l.contextFields(ctx, fields))
+	}
+}
+
+func (l *Logger) DebugCtx(ctx context.Context, msg string, fields Fields) {
+	if l.enabled(LoggerLevelDebug) {
+		l.Debug(msg, l.contextFields(ctx, fields))
+	}
+}
+
+func (l *Logger) InfoCtx(ctx context.Context, msg string, fields Fields) {
+	if l.enabled(LoggerLevelInfo) {
+		l.Info(msg, l.contextFields(ctx, fields))
+	}
+}
+
+func (l *Logger) WarnCtx(ctx context.Context, msg string, fields Fields) {
+	if l.enabled(LoggerLevelWarn) {
+		l.Warn(msg, l.contextFields(ctx, fields))
+	}
+}
+
+func (l *Logger) ErrorCtx(ctx context.Context, msg string, fields Fields) error {
+	return l.Error(msg, l.contextFields(ctx, fields))
+}
+
+func (l *Logger) FatalCtx(ctx context.Context, msg string, fields Fields) {
+	l.Fatal(msg, l.contextFields(ctx, fields))
+}
+
+func (l *Logger) PanicCtx(ctx context.Context, msg string, fields Fields) {
+	l.Panic(msg, l.contextFields(ctx, fields))
+}
+
+func TraceCtx(ctx context.Context, msg string, fields Fields) {
+	defaultLogger.TraceCtx(ctx, msg, fields)
+}
+
+func DebugCtx(ctx context.Context, msg string, fields Fields) {
+	defaultLogger.DebugCtx(ctx, msg, fields)
+}
+
+func InfoCtx(ctx context.Context, msg string, fields Fields) {
+	defaultLogger.InfoCtx(ctx, msg, fields)
+}
+
+func WarnCtx(ctx context.Context, msg string, fields Fields) {
+	defaultLogger.WarnCtx(ctx, msg, fields)
+}
+
+func ErrorCtx(ctx context.Context, msg string, fields Fields) error {
+	return defaultLogger.ErrorCtx(ctx, msg, fields)
+}
+
+func FatalCtx(ctx context.Context, msg string, fields Fields) {
+	defaultLogger.FatalCtx(ctx, msg, fields)
+}
+
+func PanicCtx(ctx context.Context, msg string, fields Fields) {
+	defaultLogger.PanicCtx(ctx, msg, fields)
+}
@@ -0,0 +1,114 @@
+package bayaan
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var ansiEscape = regexp.MustCompile("\033\\[[0-9;]*m")
+
+// Decoder reads entries back from bayaan's text output.
+type Decoder struct {
+	// TimeFormat is the layout the entries were written with.
+	TimeFormat string
+	// Location is used for layouts without a zone. Defaults to time.Local.
+	Location *time.Location
+
+	scanner *bufio.Scanner
+	pending string
+	hasLine bool
+}
+
+func NewDecoder(r io.Reader) *Decoder {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	return &Decoder{
+		TimeFormat: DefaultTimeFormat,
+		Location:   time.Local,
+		scanner:    scanner,
+	}
+}
+
+func (d *Decoder) next() (string, bool) {
+	if d.hasLine {
+		d.hasLine = false
+		return d.pending, true
+	}
+	if !d.scanner.Scan() {
+		return "", false
+	}
+	return ansiEscape.ReplaceAllString(d.scanner.Text(), ""), true
+}
+
+func (d *Decoder) unread(line string) {
+	d.pending = line
+	d.hasLine = true
+}
+
+func parseHeader(line string) (LoggerLevel, string, bool) {
+	name, msg, ok := strings.Cut(line, ": ")
+	if !ok {
+		return 0, "", false
+	}
+	level, err := ParseLevel(name)
+	if err != nil || name != level.String() {
+		return 0, "", false
+	}
+	return level, msg, true
+}
+
+// Decode returns the next entry, or io.EOF when the input is exhausted.
+// Lines that do not belong to any entry are skipped.
+func (d *Decoder) Decode() (*Entry, error) {
+	var entry *Entry
+	var indent string
+	for {
+		line, ok := d.next()
+		if !ok {
+			break
+		}
+
+		if level, msg, ok := parseHeader(line); ok {
+			if entry != nil {
+				d.unread(line)
+				return entry, nil
+			}
+			entry = &Entry{Level: level, Message: msg, Fields: make(Fields)}
+			indent = strings.Repeat(" ", len(level.String())+2)
+			continue
+		}
+		// Colored outputs leave the reset code on a line of its own.
+		if entry == nil || line == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(line, indent) {
+			entry.Message += "\n" + line
+			continue
+		}
+		key, value, ok := strings.Cut(line[len(indent):], ": ")
+		if !ok {
+			entry.Message += "\n" + line
+			continue
+		}
+		value = strings.TrimSuffix(value, " ")
+		if key == "time" && entry.Time.IsZero() {
+			if t, err := parseTime(d.TimeFormat, value, d.Location); err == nil {
+				entry.Time = t
+				continue
+			}
+		}
+		entry.Fields[key] = value
+	}
+
+	if err := d.scanner.Err(); err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, io.EOF
+	}
+	return entry, nil
+}
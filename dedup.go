@@ -0,0 +1,75 @@
+package bayaan
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// dedup collapses runs of identical entries. It is only touched by the
+// writer goroutine.
+type dedup struct {
+	window  time.Duration
+	last    logEntry
+	hasLast bool
+	repeats int
+	since   time.Time
+}
+
+func sameEntry(a, b logEntry) bool {
+	return a.level == b.level && a.msg == b.msg && a.logger == b.logger &&
+		reflect.DeepEqual(a.fields, b.fields) && reflect.DeepEqual(a.typed, b.typed)
+}
+
+// check reports whether entry repeats the previous one and should be
+// suppressed, along with a summary of earlier repeats that is due now.
+func (d *dedup) check(entry logEntry, now time.Time) (bool, *logEntry) {
+	if d.hasLast && sameEntry(d.last, entry) {
+		if d.repeats == 0 {
+			d.since = now
+		}
+		d.repeats++
+		if d.window > 0 && now.Sub(d.since) >= d.window {
+			return true, d.flush()
+		}
+		return true, nil
+	}
+
+	summary := d.flush()
+	d.last = entry
+	d.last.done = nil
+	d.hasLast = true
+	return false, summary
+}
+
+func (d *dedup) flush() *logEntry {
+	if d.repeats == 0 {
+		return nil
+	}
+	fields := make(Fields, len(d.last.fields)+1)
+	for k, v := range d.last.fields {
+		fields[k] = v
+	}
+	fields["repeated"] = d.repeats
+	summary := &logEntry{
+		level:  d.last.level,
+		msg:    fmt.Sprintf("message repeated %d times: [%s]", d.repeats, d.last.msg),
+		fields: fields,
+		typed:  d.last.typed,
+		logger: d.last.logger,
+	}
+	d.repeats = 0
+	return summary
+}
+
+// WithDedup collapses identical consecutive entries (same level, message
+// and fields) into the first one followed by a single "message repeated N
+// times" entry. During a long run of repeats a summary is written at least
+// once per window; a window of 0 waits for the run to end.
+func WithDedup(window time.Duration) LoggerOption {
+	return func(l *Logger) {
+		l.mu.Lock()
+		l.dedup = &dedup{window: window}
+		l.mu.Unlock()
+	}
+}
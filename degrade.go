@@ -0,0 +1,57 @@
+package bayaan
+
+import (
+	"os"
+	"sync/atomic"
+)
+
+// circuitThreshold is the number of consecutive failed writes after which
+// an output's circuit is considered open.
+const circuitThreshold = 5
+
+// outputHealth tracks write failures for one output. It is shared by every
+// logger derived from the one the output was registered on.
+type outputHealth struct {
+	busy     atomic.Bool   // set while an abandoned timed-out write is pending
+	failures atomic.Int64  // consecutive failed writes
+	errors   atomic.Uint64 // failed writes in total
+	dropped  atomic.Uint64 // entries dropped while the output's writer goroutine was full
+	behind   atomic.Bool   // the last entry was dropped that way
+}
+
+// report records the outcome of a write.
+func (h *outputHealth) report(err error) {
+	if err != nil {
+		h.failures.Add(1)
+		h.errors.Add(1)
+	} else {
+		h.failures.Store(0)
+	}
+}
+
+func (h *outputHealth) open() bool {
+	return h.failures.Load() >= circuitThreshold
+}
+
+// degrade writes WARN and above to stderr while every output's circuit is
+// open, so operators still see something during a logging outage. Outputs
+// keep being tried, and the fallback stops as soon as one of them recovers.
+func (l *Logger) degrade(e *Entry, line []byte, outputs []output) {
+	if len(outputs) == 0 {
+		return
+	}
+	for _, out := range outputs {
+		if !out.health.open() {
+			if l.state.degraded.CompareAndSwap(true, false) {
+				l.warnf("Logger output recovered, leaving stderr fallback")
+			}
+			return
+		}
+	}
+	if l.state.degraded.CompareAndSwap(false, true) {
+		l.warnf("Logger outputs are all failing, falling back to stderr for WARN and above")
+	}
+	if e.Level.AtLeast(LoggerLevelWarn) {
+		os.Stderr.Write(line)
+	}
+}
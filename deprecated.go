@@ -0,0 +1,85 @@
+package bayaan
+
+import (
+	"sort"
+	"sync"
+)
+
+// Deprecation describes a deprecated feature that was used by the process.
+type Deprecation struct {
+	Feature string
+	Removal string // version the feature is due to be removed in
+	Uses    int
+}
+
+// deprecations records every feature passed to Deprecated, process-wide.
+var deprecations = struct {
+	sync.Mutex
+	seen map[string]*Deprecation
+}{seen: make(map[string]*Deprecation)}
+
+// Deprecated logs a WARN entry announcing that feature is deprecated and
+// will be removed in the removal version. Each feature is logged once per
+// process however often it is used, with the fields deprecated=true,
+// feature and removal_version alongside fields. It is meant for libraries
+// built on bayaan to report use of their own deprecated APIs.
+func (l *Logger) Deprecated(feature, removal string, fields Fields) {
+	deprecations.Lock()
+	d := deprecations.seen[feature]
+	first := d == nil
+	if first {
+		d = &Deprecation{Feature: feature, Removal: removal}
+		deprecations.seen[feature] = d
+	}
+	d.Uses++
+	deprecations.Unlock()
+	if !first {
+		return
+	}
+
+	f := make(Fields, len(fields)+3)
+	for k, v := range fields {
+		f[k] = v
+	}
+	f["deprecated"] = true
+	f["feature"] = feature
+	f["removal_version"] = removal
+	l.log(LoggerLevelWarn, feature+" is deprecated and will be removed in "+removal, f)
+}
+
+// Deprecations returns every deprecated feature used so far, sorted by
+// name.
+func Deprecations() []Deprecation {
+	deprecations.Lock()
+	defer deprecations.Unlock()
+	report := make([]Deprecation, 0, len(deprecations.seen))
+	for _, d := range deprecations.seen {
+		report = append(report, *d)
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].Feature < report[j].Feature })
+	return report
+}
+
+// WithDeprecationReport logs a WARN entry per deprecated feature used,
+// with its use count, when the logger is closed.
+func WithDeprecationReport() LoggerOption {
+	return func(l *Logger) {
+		l.mu.Lock()
+		l.stops = append(l.stops, func() {
+			for _, d := range Deprecations() {
+				l.enqueue(LoggerLevelWarn, "deprecated feature used", Fields{
+					"deprecated":      true,
+					"feature":         d.Feature,
+					"removal_version": d.Removal,
+					"uses":            d.Uses,
+				})
+			}
+		})
+		l.mu.Unlock()
+	}
+}
+
+// Deprecated calls Deprecated on the default logger.
+func Deprecated(feature, removal string, fields Fields) {
+	defaultLogger.Deprecated(feature, removal, fields)
+}
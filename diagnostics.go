@@ -0,0 +1,89 @@
+package bayaan
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// WithDiagnostics redirects the logger's own warnings (dropped entries,
+// failing hooks and sinks, watchdog reports) from stderr to w.
+func WithDiagnostics(w io.Writer) LoggerOption {
+	return func(l *Logger) {
+		l.mu.Lock()
+		l.diag = w
+		l.mu.Unlock()
+	}
+}
+
+func (l *Logger) warnf(format string, args ...interface{}) {
+	l.mu.RLock()
+	w := l.diag
+	l.mu.RUnlock()
+	if w == nil {
+		w = os.Stderr
+	}
+	fmt.Fprintf(w, "Warning: "+format+"\n", args...)
+}
+
+// WithErrorHandler calls fn with the name of the output and the error
+// whenever a write to an output or sink fails, including writes abandoned
+// by WithWriteTimeout. Outputs are named after what they write to: stdout,
+// stderr, a file path, a network or HTTP address, or their type. Metrics
+// reports how many writes to each output have failed in a row. fn runs on
+// the goroutine writing the output, so it must not block, and must not log
+// to this logger when WithSync is set. It replaces the diagnostics warning
+// failing sinks otherwise produce.
+func WithErrorHandler(fn func(output string, err error)) LoggerOption {
+	return func(l *Logger) {
+		l.mu.Lock()
+		l.onError = fn
+		l.mu.Unlock()
+	}
+}
+
+// outputFailed reports a failed write to out.
+func (l *Logger) outputFailed(out output, err error) {
+	l.mu.RLock()
+	fn := l.onError
+	l.mu.RUnlock()
+	if fn != nil {
+		fn(outputName(out), err)
+	} else if out.sink != nil {
+		l.warnf("Logger sink failed: %v", err)
+	}
+}
+
+// outputName describes what out writes to.
+func outputName(out output) string {
+	var dst interface{} = out.writer
+	if out.sink != nil {
+		dst = out.sink
+	}
+	switch d := dst.(type) {
+	case *os.File:
+		switch d {
+		case os.Stdout:
+			return "stdout"
+		case os.Stderr:
+			return "stderr"
+		}
+		return d.Name()
+	case *appendFile:
+		return d.path
+	case *RotatingFile:
+		return d.path
+	case *NetworkWriter:
+		return d.network + "://" + d.addr
+	case *HTTPSink:
+		return d.url
+	case *WebhookSink:
+		return d.url
+	case *SyslogSink:
+		if d.addr != "" {
+			return "syslog " + d.network + "://" + d.addr
+		}
+		return "syslog"
+	}
+	return fmt.Sprintf("%T", dst)
+}
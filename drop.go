@@ -0,0 +1,56 @@
+package bayaan
+
+// WithOnDrop calls fn with every entry dropped because the queue was full,
+// instead of printing a warning to the diagnostics writer. fn runs on the
+// goroutine that logged the entry and must not block or log through the
+// same logger.
+func WithOnDrop(fn func(Entry)) LoggerOption {
+	return func(l *Logger) {
+		l.mu.Lock()
+		l.onDrop = fn
+		l.mu.Unlock()
+	}
+}
+
+// Dropped returns the number of entries dropped so far because the queue
+// was full. The count is shared with every logger derived from l.
+func (l *Logger) Dropped() uint64 {
+	return l.state.dropped.Load()
+}
+
+// Dropped returns the number of entries the default logger dropped.
+func Dropped() uint64 {
+	return defaultLogger.Dropped()
+}
+
+func (l *Logger) drop(entry logEntry) {
+	l.state.dropped.Add(1)
+	l.mu.RLock()
+	fn := l.onDrop
+	redactKeys := l.redact
+	var fields Fields
+	if fn != nil {
+		fields = make(Fields, len(l.fields)+len(entry.fields))
+		for k, v := range l.fields {
+			fields[k] = v
+		}
+	}
+	l.mu.RUnlock()
+	if fn == nil {
+		l.warnf("Logger channel full, dropping message: %s", entry.msg)
+		return
+	}
+	l.live.addTo(fields)
+
+	for k, v := range entry.allFields() {
+		fields[k] = v
+	}
+	for k, v := range fields {
+		fields[k] = redact(k, resolve(v), redactKeys, 0)
+	}
+	at := entry.time
+	if at.IsZero() {
+		at = l.now()
+	}
+	fn(Entry{Level: entry.level, Time: at, Message: entry.msg, Fields: fields, Stack: entry.stack})
+}
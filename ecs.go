@@ -0,0 +1,99 @@
+package bayaan
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// ecsVersion is the Elastic Common Schema version ECSFormatter follows.
+const ecsVersion = "8.11.0"
+
+// ECSFormatter renders each entry as Elastic Common Schema JSON:
+//
+//	{"@timestamp":"2024-05-01T12:00:00.000Z","log.level":"info","message":"...","ecs.version":"8.11.0",...}
+//
+// The timestamp is UTC with millisecond precision. The "error" field
+// becomes error.message, error.type and error.stack_trace, "trace_id" and
+// "span_id" become trace.id and span.id, and the "logger" field of named
+// loggers becomes log.logger. Other fields follow in key order; those
+// colliding with the keys above are prefixed with "fields.".
+type ECSFormatter struct {
+	json JSONFormatter
+}
+
+// ecsRenamed maps field keys to the ECS keys they are written as.
+var ecsRenamed = map[string]string{
+	"logger":   "log.logger",
+	"trace_id": "trace.id",
+	"span_id":  "span.id",
+}
+
+func (f *ECSFormatter) Format(e *Entry) ([]byte, error) {
+	return f.AppendFormat(nil, e)
+}
+
+// AppendFormat appends the ECS rendering of e to dst.
+func (f *ECSFormatter) AppendFormat(dst []byte, e *Entry) ([]byte, error) {
+	buf := bytes.NewBuffer(dst)
+	buf.WriteString(`{"@timestamp":"`)
+	buf.WriteString(e.Time.UTC().Format("2006-01-02T15:04:05.000Z"))
+	buf.WriteString(`","log.level":`)
+	f.json.writeString(buf, strings.ToLower(e.Level.String()))
+	buf.WriteString(`,"message":`)
+	f.json.writeString(buf, e.Message)
+	buf.WriteString(`,"ecs.version":"` + ecsVersion + `"`)
+
+	for _, k := range sortedKeys(e.Fields) {
+		v := e.Fields[k]
+		if k == "error" {
+			f.writeError(buf, v)
+			continue
+		}
+		name, ok := ecsRenamed[k]
+		if !ok {
+			name = k
+			switch k {
+			case "@timestamp", "log.level", "message", "ecs.version", "log.logger", "trace.id", "span.id":
+				name = "fields." + k
+			default:
+				if strings.HasPrefix(k, "error.") {
+					name = "fields." + k
+				}
+			}
+		}
+		buf.WriteByte(',')
+		f.json.writeString(buf, name)
+		buf.WriteByte(':')
+		f.json.writeValue(buf, v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// writeError writes the "error" field as error.* keys. Details from Err
+// keep their other keys, such as the unwrapped chain, under error.
+func (f *ECSFormatter) writeError(buf *bytes.Buffer, v interface{}) {
+	var d Fields
+	switch v := v.(type) {
+	case Fields:
+		d = v
+	case error:
+		d = Fields{"message": errorText(v), "type": fmt.Sprintf("%T", v)}
+		if st := stackTrace(v); st != "" {
+			d["stack"] = st
+		}
+	default:
+		d = Fields{"message": v}
+	}
+	for _, k := range sortedKeys(d) {
+		name := k
+		if k == "stack" {
+			name = "stack_trace"
+		}
+		buf.WriteByte(',')
+		f.json.writeString(buf, "error."+name)
+		buf.WriteByte(':')
+		f.json.writeValue(buf, d[k])
+	}
+}
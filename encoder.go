@@ -0,0 +1,35 @@
+package bayaan
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Value is what a registered encoder renders a field value as.
+type Value = interface{}
+
+var (
+	encoders     sync.Map // reflect.Type -> func(interface{}) Value
+	encoderCount atomic.Int32
+)
+
+// RegisterEncoder makes every field value of type T render as fn's result,
+// in all outputs, sinks and hooks. Registering a type again replaces its
+// encoder. Only top-level field values are encoded.
+func RegisterEncoder[T any](fn func(T) Value) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if _, loaded := encoders.Swap(t, func(v interface{}) Value { return fn(v.(T)) }); !loaded {
+		encoderCount.Add(1)
+	}
+}
+
+func encodeValue(v interface{}) interface{} {
+	if v == nil || encoderCount.Load() == 0 {
+		return v
+	}
+	if fn, ok := encoders.Load(reflect.TypeOf(v)); ok {
+		return fn.(func(interface{}) Value)(v)
+	}
+	return v
+}
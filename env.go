@@ -0,0 +1,119 @@
+package bayaan
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// OptionsFromEnv builds options from the environment:
+//
+//	BAYAAN_LEVEL    minimum level: trace, debug, info, warn, error, fatal or panic
+//	BAYAAN_FORMAT   text, compact, json, ecs or gcp
+//	BAYAAN_OUTPUTS  comma-separated outputs replacing the configured ones:
+//	                stdout, stderr, syslog, journald, tcp://host:port,
+//	                udp://host:port, or a file path (file:// prefix optional)
+//	NO_COLOR        when set to anything non-empty, stdout and stderr are
+//	                not colored (https://no-color.org)
+//
+// Unset variables contribute nothing.
+func OptionsFromEnv() ([]LoggerOption, error) {
+	var options []LoggerOption
+
+	if s := os.Getenv("BAYAAN_LEVEL"); s != "" {
+		level, err := ParseLevel(s)
+		if err != nil {
+			return nil, fmt.Errorf("BAYAAN_LEVEL: %w", err)
+		}
+		options = append(options, WithLevel(level))
+	}
+
+	switch s := strings.ToLower(os.Getenv("BAYAAN_FORMAT")); s {
+	case "":
+	case "text":
+		options = append(options, WithFormatter(nil))
+	case "compact":
+		options = append(options, WithFormatter(&TextFormatter{Compact: true}))
+	case "json":
+		options = append(options, WithFormatter(&JSONFormatter{}))
+	case "ecs":
+		options = append(options, WithFormatter(&ECSFormatter{}))
+	case "gcp":
+		options = append(options, WithFormatter(&GCPFormatter{}))
+	default:
+		return nil, fmt.Errorf("BAYAAN_FORMAT: unknown format %q", s)
+	}
+
+	if s := os.Getenv("BAYAAN_OUTPUTS"); s != "" {
+		color := os.Getenv("NO_COLOR") == ""
+		options = append(options, withoutOutputs())
+		for _, spec := range strings.Split(s, ",") {
+			option, err := envOutput(strings.TrimSpace(spec), color)
+			if err != nil {
+				return nil, fmt.Errorf("BAYAAN_OUTPUTS: %w", err)
+			}
+			options = append(options, option)
+		}
+	}
+	return options, nil
+}
+
+func envOutput(spec string, color bool) (LoggerOption, error) {
+	switch {
+	case spec == "stdout":
+		return WithOutput(os.Stdout, true, color), nil
+	case spec == "stderr":
+		return WithOutput(os.Stderr, true, color), nil
+	case spec == "syslog":
+		return WithSyslogOutput("", ""), nil
+	case spec == "journald":
+		return WithJournaldOutput(filepath.Base(os.Args[0])), nil
+	case strings.HasPrefix(spec, "tcp://"), strings.HasPrefix(spec, "udp://"):
+		network, addr, _ := strings.Cut(spec, "://")
+		return WithNetworkOutput(network, addr), nil
+	case spec == "":
+		return nil, fmt.Errorf("empty output")
+	}
+
+	return fileOutput(strings.TrimPrefix(spec, "file://"))
+}
+
+// fileOutput opens path for appending and adds it as an output, closed
+// with the logger.
+func fileOutput(path string) (LoggerOption, error) {
+	f, err := openAppendFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return func(l *Logger) {
+		l.addOutput(output{writer: f, closer: f}, true)
+	}, nil
+}
+
+// withoutOutputs removes every output configured so far, closing those
+// the logger opened.
+func withoutOutputs() LoggerOption {
+	return func(l *Logger) {
+		l.mu.Lock()
+		dropped := l.outputs
+		l.outputs = nil
+		l.mu.Unlock()
+		closeOutputs(dropped)
+	}
+}
+
+// SetupFromEnv initializes the default logger with options followed by
+// those from OptionsFromEnv, so the environment overrides what the program
+// configures. On an invalid variable the default logger is left unchanged.
+func SetupFromEnv(options ...LoggerOption) error {
+	envOptions, err := OptionsFromEnv()
+	if err != nil {
+		return err
+	}
+	if len(options) == 0 {
+		options = []LoggerOption{WithOutput(os.Stdout, false, os.Getenv("NO_COLOR") == "")}
+	}
+	Setup(append(options, envOptions...)...)
+	return nil
+}
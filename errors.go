@@ -0,0 +1,65 @@
+package bayaan
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// maxErrorDepth bounds how far errorDetails follows Unwrap, in case an
+// error chain loops back on itself.
+const maxErrorDepth = 32
+
+// errorDetails describes err as nested fields:
+//
+//	message, type   of err itself
+//	stack           the first stack trace carried along the chain
+//	chain           message and type of each error reached through Unwrap
+//	causes          details of each error joined by errors.Join or similar
+func errorDetails(err error) Fields {
+	d := Fields{"message": errorText(err), "type": fmt.Sprintf("%T", err)}
+
+	var chain []Fields
+	for cur, depth := err, 0; cur != nil && depth < maxErrorDepth; depth++ {
+		if _, ok := d["stack"]; !ok {
+			if st := stackTrace(cur); st != "" {
+				d["stack"] = st
+			}
+		}
+		if multi, ok := cur.(interface{ Unwrap() []error }); ok {
+			var causes []Fields
+			for _, cause := range multi.Unwrap() {
+				if cause != nil && depth+1 < maxErrorDepth {
+					causes = append(causes, errorDetails(cause))
+				}
+			}
+			d["causes"] = causes
+			break
+		}
+		cur = errors.Unwrap(cur)
+		if cur != nil {
+			chain = append(chain, Fields{"message": errorText(cur), "type": fmt.Sprintf("%T", cur)})
+		}
+	}
+	if len(chain) > 0 {
+		d["chain"] = chain
+	}
+	return d
+}
+
+// stackTrace renders the result of err's StackTrace method, if it has one
+// taking no arguments, with %+v. The method is found by reflection so any
+// stack type (github.com/pkg/errors.StackTrace and the like) works without
+// a dependency.
+func stackTrace(err error) string {
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return ""
+	}
+	var st string
+	func() {
+		defer func() { recover() }()
+		st = fmt.Sprintf("%+v", m.Call(nil)[0].Interface())
+	}()
+	return st
+}
@@ -0,0 +1,91 @@
+package bayaan
+
+import "os"
+
+// ExitCoder is implemented by field values, typically errors, that carry
+// their own process exit status. Fatal exits with the code of the "error"
+// field when it is one, or else of the first in key order.
+type ExitCoder interface {
+	ExitCode() int
+}
+
+type exitCodes struct {
+	key   string
+	codes map[string]int
+}
+
+// WithExitCode makes Fatal exit with codes[v] when the entry's field key
+// has the value v, compared by its printed form. Entries without a match
+// exit with an ExitCoder field's code, or 1.
+func WithExitCode(key string, codes map[string]int) LoggerOption {
+	return func(l *Logger) {
+		l.mu.Lock()
+		l.exitCodes = &exitCodes{key: key, codes: codes}
+		l.mu.Unlock()
+	}
+}
+
+func (l *Logger) exitCode(fields Fields) int {
+	l.mu.RLock()
+	mapping := l.exitCodes
+	l.mu.RUnlock()
+	var value interface{}
+	var found bool
+	if mapping != nil {
+		value, found = fields[mapping.key]
+		if !found {
+			value, found = l.Fields()[mapping.key]
+		}
+	}
+
+	if found {
+		if code, ok := mapping.codes[sprint(value)]; ok {
+			return code
+		}
+	}
+	if c, ok := fields["error"].(ExitCoder); ok {
+		return c.ExitCode()
+	}
+	for _, k := range sortedKeys(fields) {
+		if c, ok := fields[k].(ExitCoder); ok {
+			return c.ExitCode()
+		}
+	}
+	return 1
+}
+
+// WithExitFunc replaces os.Exit as the way Fatal ends the process, so tests
+// can observe the exit code instead of dying. If fn returns, so does Fatal.
+func WithExitFunc(fn func(code int)) LoggerOption {
+	return func(l *Logger) {
+		l.mu.Lock()
+		l.exitFunc = fn
+		l.mu.Unlock()
+	}
+}
+
+// WithFatalHook registers fn to run after a Fatal entry is logged, just
+// before the process exits with code. Hooks run in registration order, on
+// the goroutine that called Fatal.
+func WithFatalHook(fn func(code int)) LoggerOption {
+	return func(l *Logger) {
+		l.mu.Lock()
+		l.onFatal = append(l.onFatal, fn)
+		l.mu.Unlock()
+	}
+}
+
+// exit runs the fatal hooks, then exits with code.
+func (l *Logger) exit(code int) {
+	l.mu.RLock()
+	hooks := l.onFatal
+	exit := l.exitFunc
+	l.mu.RUnlock()
+	for _, hook := range hooks {
+		hook(code)
+	}
+	if exit == nil {
+		exit = os.Exit
+	}
+	exit(code)
+}
@@ -0,0 +1,31 @@
+package bayaan_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/ahmedsat/bayaan"
+)
+
+type exitError struct{ code int }
+
+func (e exitError) Error() string { return "exit error" }
+func (e exitError) ExitCode() int { return e.code }
+
+func TestExitCodeFromErr(t *testing.T) {
+	code := -1
+	l := bayaan.NewLogger(bayaan.WithOutput(io.Discard, false, false),
+		bayaan.WithExitFunc(func(c int) { code = c }))
+	defer l.Close()
+
+	l.FatalT("fatal", bayaan.Err(exitError{code: 3}))
+	if code != 3 {
+		t.Errorf("FatalT with Err: exit code = %d, want 3", code)
+	}
+
+	code = -1
+	l.Check(bayaan.LoggerLevelFatal, "fatal").Write(bayaan.Err(exitError{code: 4}))
+	if code != 4 {
+		t.Errorf("Check(FATAL).Write with Err: exit code = %d, want 4", code)
+	}
+}
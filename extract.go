@@ -0,0 +1,92 @@
+package bayaan
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Extract reads the given log files, plain or gzip-compressed, and returns
+// the entries whose time falls within [from, to] and for which filter
+// returns true, ordered by time. A zero from or to leaves that side of the
+// window open, and a nil filter accepts every entry.
+func Extract(files []string, from, to time.Time, filter func(*Entry) bool) ([]*Entry, error) {
+	var entries []*Entry
+	for _, name := range files {
+		// A file last written before the window starts holds nothing in it.
+		if !from.IsZero() {
+			if info, err := os.Stat(name); err == nil && info.ModTime().Before(from) {
+				continue
+			}
+		}
+
+		if err := extractFile(name, func(e *Entry) {
+			if !from.IsZero() && e.Time.Before(from) {
+				return
+			}
+			if !to.IsZero() && e.Time.After(to) {
+				return
+			}
+			if filter != nil && !filter(e) {
+				return
+			}
+			entries = append(entries, e)
+		}); err != nil {
+			return entries, err
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.Before(entries[j].Time)
+	})
+	return entries, nil
+}
+
+func openLogFile(name string) (io.ReadCloser, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(name, ".gz") {
+		return f, nil
+	}
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &gzipFile{Reader: gz, file: f}, nil
+}
+
+type gzipFile struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (g *gzipFile) Close() error {
+	g.Reader.Close()
+	return g.file.Close()
+}
+
+func extractFile(name string, fn func(*Entry)) error {
+	r, err := openLogFile(name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	dec := NewDecoder(r)
+	for {
+		e, err := dec.Decode()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		fn(e)
+	}
+}
@@ -0,0 +1,71 @@
+package bayaan
+
+import "sync/atomic"
+
+// failover marks a backup output, written only while the outputs ahead of
+// it in its chain fail.
+type failover struct {
+	ahead  []*outputHealth
+	active atomic.Bool
+}
+
+// engaged reports whether the last write to every output ahead failed.
+func (f *failover) engaged() bool {
+	for _, h := range f.ahead {
+		if h.failures.Load() == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// WithFailover chains outputs so that each backup is written only while
+// the outputs before it fail: the outputs added by primary receive every
+// entry, those added by the first backup receive entries while the
+// primary's last write failed, and so on. The primary keeps being tried,
+// and the backups stand down as soon as it writes successfully again, so
+// a local file can carry the logs through a collector outage:
+//
+//	bayaan.WithFailover(
+//		bayaan.WithSyslogOutput("tcp", "logs.internal:514"),
+//		bayaan.WithRotatingFile("/var/log/app/fallback.log", 100, 0, 3),
+//	)
+//
+// Only outputs whose writes fail while their destination is down can act
+// as primaries; NetworkWriter buffers instead. With WithWriters the
+// backups may take over an entry or two late. The options should add
+// outputs rather than replace them. Switches are reported through the
+// diagnostics writer.
+func WithFailover(primary LoggerOption, backups ...LoggerOption) LoggerOption {
+	return func(l *Logger) {
+		var chain []*outputHealth
+		l.editAdded(primary, func(out *output) {
+			chain = append(chain, out.health)
+		})
+		for _, backup := range backups {
+			f := &failover{ahead: append([]*outputHealth(nil), chain...)}
+			l.editAdded(backup, func(out *output) {
+				out.failover = f
+				chain = append(chain, out.health)
+			})
+		}
+	}
+}
+
+// standby reports whether out is a backup with nothing to do, reporting
+// when it takes over and hands back.
+func (l *Logger) standby(out output) bool {
+	f := out.failover
+	if f == nil {
+		return false
+	}
+	engaged := f.engaged()
+	if f.active.CompareAndSwap(!engaged, engaged) {
+		if engaged {
+			l.warnf("Logger outputs failing, writing to failover output %s", outputName(out))
+		} else {
+			l.warnf("Logger outputs recovered, failover output %s standing down", outputName(out))
+		}
+	}
+	return !engaged
+}
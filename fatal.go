@@ -0,0 +1,42 @@
+package bayaan
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type deferredFatal struct {
+	shutdown func(ctx context.Context)
+	deadline time.Duration
+	once     sync.Once
+}
+
+func (f *deferredFatal) trigger(l *Logger, code int) {
+	f.once.Do(func() {
+		var exited sync.Once
+		exit := func() { exited.Do(func() { l.exit(code) }) }
+		go func() {
+			time.AfterFunc(f.deadline, exit)
+
+			ctx, cancel := context.WithTimeout(context.Background(), f.deadline)
+			f.shutdown(ctx)
+			cancel()
+
+			l.Flush()
+			exit()
+		}()
+	})
+}
+
+// WithDeferredFatal makes Fatal return instead of exiting at once. The
+// first Fatal runs shutdown in the background with a context that expires
+// after deadline, waits for queued entries to be written, then exits. If
+// shutdown overruns the deadline the process exits anyway.
+func WithDeferredFatal(shutdown func(ctx context.Context), deadline time.Duration) LoggerOption {
+	return func(l *Logger) {
+		l.mu.Lock()
+		l.fatal = &deferredFatal{shutdown: shutdown, deadline: deadline}
+		l.mu.Unlock()
+	}
+}
@@ -0,0 +1,286 @@
+package bayaan
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
+)
+
+type fieldKind uint8
+
+const (
+	kindAny fieldKind = iota
+	kindString
+	kindInt64
+	kindUint64
+	kindFloat64
+	kindBool
+	kindDuration
+	kindError // an error, expanded by errorDetails when read with Value
+)
+
+// Field is a typed key/value pair for the *T logging methods. Scalars are
+// stored unboxed, so building fields and queueing the entry does not
+// allocate the map and interface values a Fields literal needs; the map is
+// built by the writer goroutine instead.
+type Field struct {
+	Key  string
+	kind fieldKind
+	num  uint64
+	str  string
+	any  interface{}
+}
+
+// String returns a field holding a string.
+func String(key, value string) Field {
+	return Field{Key: key, kind: kindString, str: value}
+}
+
+// Int returns a field holding an int, stored as an int64.
+func Int(key string, value int) Field {
+	return Int64(key, int64(value))
+}
+
+// Int64 returns a field holding an int64.
+func Int64(key string, value int64) Field {
+	return Field{Key: key, kind: kindInt64, num: uint64(value)}
+}
+
+// Uint64 returns a field holding a uint64.
+func Uint64(key string, value uint64) Field {
+	return Field{Key: key, kind: kindUint64, num: value}
+}
+
+// Float64 returns a field holding a float64.
+func Float64(key string, value float64) Field {
+	return Field{Key: key, kind: kindFloat64, num: math.Float64bits(value)}
+}
+
+// Bool returns a field holding a bool.
+func Bool(key string, value bool) Field {
+	f := Field{Key: key, kind: kindBool}
+	if value {
+		f.num = 1
+	}
+	return f
+}
+
+// Duration returns a field holding a time.Duration, written as its
+// String form ("1.5s").
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, kind: kindDuration, num: uint64(value)}
+}
+
+// Time returns a field holding a time.Time. JSON output writes it in
+// RFC 3339 form.
+func Time(key string, value time.Time) Field {
+	return Field{Key: key, any: value}
+}
+
+// Err returns an "error" field describing err: its message and type, the
+// errors reached through Unwrap, and the stack trace when the error carries
+// one (a StackTrace method, as github.com/pkg/errors provides). A nil err
+// is recorded as nil. The details are gathered when the entry is written;
+// until then err itself is kept, so FatalT exits with its code when it is
+// an ExitCoder.
+func Err(err error) Field {
+	if err == nil {
+		return Field{Key: "error"}
+	}
+	return Field{Key: "error", kind: kindError, any: err}
+}
+
+// hexDumpLimit is how many bytes of a Hex field are dumped.
+const hexDumpLimit = 64
+
+// Hex returns a field holding a one-line hex dump of b: its length, the
+// first 64 bytes in hex and as ASCII, and how many bytes were left out:
+//
+//	payload: 300 bytes: 16 03 01 02 00 01 00 01 fc 03 03 ... |............| +236 bytes
+//
+// The dump is made when Hex is called, so b may be reused afterwards.
+func Hex(key string, b []byte) Field {
+	return Field{Key: key, kind: kindString, str: hexDump(b)}
+}
+
+func hexDump(b []byte) string {
+	shown := b
+	if len(shown) > hexDumpLimit {
+		shown = shown[:hexDumpLimit]
+	}
+	dump := make([]byte, 0, 32+4*len(shown))
+	dump = strconv.AppendInt(dump, int64(len(b)), 10)
+	dump = append(dump, " bytes"...)
+	if len(b) == 0 {
+		return string(dump)
+	}
+	dump = append(dump, ':')
+	for _, c := range shown {
+		dump = append(dump, ' ', hexDigits[c>>4], hexDigits[c&0xf])
+	}
+	dump = append(dump, " |"...)
+	for _, c := range shown {
+		if c < 0x20 || c > 0x7e {
+			c = '.'
+		}
+		dump = append(dump, c)
+	}
+	dump = append(dump, '|')
+	if len(b) > len(shown) {
+		dump = append(dump, " +"...)
+		dump = strconv.AppendInt(dump, int64(len(b)-len(shown)), 10)
+		dump = append(dump, " bytes"...)
+	}
+	return string(dump)
+}
+
+const hexDigits = "0123456789abcdef"
+
+// Any returns a field holding an arbitrary value, rendered like a value in
+// Fields.
+func Any(key string, value interface{}) Field {
+	return Field{Key: key, any: value}
+}
+
+// Group returns a field nesting fields under key. A Fields value inside
+// Fields nests the same way. Text output flattens groups into dotted keys
+// ("http.method"), JSON writes them as nested objects.
+func Group(key string, fields Fields) Field {
+	return Field{Key: key, any: fields}
+}
+
+// LazyFunc is a field value computed only if the entry is written, on the
+// goroutine writing it. It may be used in Fields as well as with Lazy.
+type LazyFunc func() interface{}
+
+// Lazy returns a field whose value is computed by fn only if the entry
+// passes the level filter and is written. fn runs on the writer goroutine,
+// so it must be safe to call concurrently with the code that logged it.
+func Lazy(key string, fn func() interface{}) Field {
+	return Field{Key: key, any: LazyFunc(fn)}
+}
+
+// resolve evaluates v if it is a LazyFunc. A panicking function is
+// rendered as the panic rather than taking the writer down.
+func resolve(v interface{}) (out interface{}) {
+	fn, ok := v.(LazyFunc)
+	if !ok {
+		return v
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			out = "<panic: " + sprint(r) + ">"
+		}
+	}()
+	return fn()
+}
+
+// Value returns the field's value as it would appear in Fields.
+func (f Field) Value() interface{} {
+	switch f.kind {
+	case kindString:
+		return f.str
+	case kindInt64:
+		return int64(f.num)
+	case kindUint64:
+		return f.num
+	case kindFloat64:
+		return math.Float64frombits(f.num)
+	case kindBool:
+		return f.num == 1
+	case kindDuration:
+		return time.Duration(f.num)
+	case kindError:
+		return errorDetails(f.any.(error))
+	}
+	return f.any
+}
+
+// fieldsOf converts typed fields to Fields for the exit-code lookup. Errors
+// given with Err are kept as they are, so ExitCoder values are found.
+func fieldsOf(typed []Field) Fields {
+	fields := make(Fields, len(typed))
+	for _, f := range typed {
+		if f.kind == kindError {
+			fields[f.Key] = f.any
+			continue
+		}
+		fields[f.Key] = f.Value()
+	}
+	return fields
+}
+
+// allFields returns the entry's fields with its typed fields merged in.
+func (e logEntry) allFields() Fields {
+	if len(e.typed) == 0 {
+		return e.fields
+	}
+	fields := make(Fields, len(e.fields)+len(e.typed))
+	for k, v := range e.fields {
+		fields[k] = v
+	}
+	for _, f := range e.typed {
+		fields[f.Key] = f.Value()
+	}
+	return fields
+}
+
+func (l *Logger) TraceT(msg string, fields ...Field) {
+	l.emit(logEntry{level: LoggerLevelTrace, msg: msg, typed: fields, logger: l})
+}
+
+func (l *Logger) DebugT(msg string, fields ...Field) {
+	l.emit(logEntry{level: LoggerLevelDebug, msg: msg, typed: fields, logger: l})
+}
+
+func (l *Logger) InfoT(msg string, fields ...Field) {
+	l.emit(logEntry{level: LoggerLevelInfo, msg: msg, typed: fields, logger: l})
+}
+
+func (l *Logger) WarnT(msg string, fields ...Field) {
+	l.emit(logEntry{level: LoggerLevelWarn, msg: msg, typed: fields, logger: l})
+}
+
+func (l *Logger) ErrorT(msg string, fields ...Field) error {
+	l.emit(logEntry{level: LoggerLevelError, msg: msg, typed: fields, logger: l})
+	return errors.New(msg)
+}
+
+func (l *Logger) FatalT(msg string, fields ...Field) {
+	l.emit(logEntry{level: LoggerLevelFatal, msg: msg, typed: fields, logger: l})
+	l.terminate(fieldsOf(fields))
+}
+
+func (l *Logger) PanicT(msg string, fields ...Field) {
+	l.emit(logEntry{level: LoggerLevelPanic, msg: msg, typed: fields, logger: l})
+	l.panic(msg)
+}
+
+func TraceT(msg string, fields ...Field) {
+	defaultLogger.TraceT(msg, fields...)
+}
+
+func DebugT(msg string, fields ...Field) {
+	defaultLogger.DebugT(msg, fields...)
+}
+
+func InfoT(msg string, fields ...Field) {
+	defaultLogger.InfoT(msg, fields...)
+}
+
+func WarnT(msg string, fields ...Field) {
+	defaultLogger.WarnT(msg, fields...)
+}
+
+func ErrorT(msg string, fields ...Field) error {
+	return defaultLogger.ErrorT(msg, fields...)
+}
+
+func FatalT(msg string, fields ...Field) {
+	defaultLogger.FatalT(msg, fields...)
+}
+
+func PanicT(msg string, fields ...Field) {
+	defaultLogger.PanicT(msg, fields...)
+}
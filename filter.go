@@ -0,0 +1,161 @@
+package bayaan
+
+// FieldFilter decides which fields an output receives. Filters apply to
+// top-level keys after redaction and hooks, so hooks still see every field.
+type FieldFilter struct {
+	keep func(key string) bool
+}
+
+// AllowFields keeps only the given keys.
+func AllowFields(keys ...string) *FieldFilter {
+	set := keySet(keys)
+	return &FieldFilter{keep: func(k string) bool { return set[k] }}
+}
+
+// DenyFields drops the given keys, such as high-cardinality or sensitive
+// ones, and keeps the rest.
+func DenyFields(keys ...string) *FieldFilter {
+	set := keySet(keys)
+	return &FieldFilter{keep: func(k string) bool { return !set[k] }}
+}
+
+// FilterFieldsFunc keeps the fields for which keep returns true.
+func FilterFieldsFunc(keep func(key string) bool) *FieldFilter {
+	return &FieldFilter{keep: keep}
+}
+
+func keySet(keys []string) map[string]bool {
+	set := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		set[k] = true
+	}
+	return set
+}
+
+// apply returns a copy of e holding only the fields f keeps.
+func (f *FieldFilter) apply(e *Entry) *Entry {
+	filtered := *e
+	filtered.Fields = make(Fields, len(e.Fields))
+	for k, v := range e.Fields {
+		if f.keep(k) {
+			filtered.Fields[k] = v
+		}
+	}
+	return &filtered
+}
+
+// WithOutputFilter applies filter to the outputs and sinks registered by
+// option, leaving the others unfiltered:
+//
+//	bayaan.NewLogger(
+//		bayaan.WithOutput(os.Stdout, false, true),
+//		bayaan.WithOutputFilter(bayaan.DenyFields("user_agent", "email"),
+//			bayaan.WithRotatingFile("/var/log/app.log", 100, 24*time.Hour, 7)),
+//	)
+func WithOutputFilter(filter *FieldFilter, option LoggerOption) LoggerOption {
+	return withView(option, func(v *outputView) { v.filter = filter })
+}
+
+// WithOutputFormatter renders the outputs registered by option with f
+// instead of the logger's formatter. Sinks are unaffected, as they receive
+// entries rather than text.
+func WithOutputFormatter(f Formatter, option LoggerOption) LoggerOption {
+	return withView(option, func(v *outputView) { v.formatter = f })
+}
+
+// WithFilter drops the entries for which keep returns false, such as
+// health-check requests or a noisy message. Filters see the entry as
+// formatters would, fields resolved and redacted, and run before hooks;
+// an entry is written only if every filter keeps it:
+//
+//	bayaan.WithFilter(func(e bayaan.Entry) bool {
+//		return e.Fields["path"] != "/healthz"
+//	})
+//
+// Unlike the level, filters run on the writer goroutine, so filtered
+// entries still pass through the queue.
+func WithFilter(keep func(Entry) bool) LoggerOption {
+	return func(l *Logger) {
+		l.mu.Lock()
+		l.filters = append(l.filters, keep)
+		l.mu.Unlock()
+	}
+}
+
+// WithOutputEntryFilter restricts the outputs and sinks registered by
+// option to the entries for which keep returns true, leaving the others
+// unaffected. keep sees entries after hooks.
+func WithOutputEntryFilter(keep func(Entry) bool, option LoggerOption) LoggerOption {
+	return withAccept(func(e *Entry) bool { return keep(*e) }, option)
+}
+
+// outputView is how an output sees entries when it differs from the
+// logger-wide rendering.
+type outputView struct {
+	filter    *FieldFilter
+	formatter Formatter
+}
+
+// withView applies option, then edits the view of each output it added.
+func withView(option LoggerOption, edit func(*outputView)) LoggerOption {
+	return func(l *Logger) {
+		l.editAdded(option, func(out *output) {
+			v := &outputView{}
+			if out.view != nil {
+				*v = *out.view
+			}
+			edit(v)
+			out.view = v
+		})
+	}
+}
+
+// editAdded applies option, then calls edit on each output it added.
+func (l *Logger) editAdded(option LoggerOption, edit func(*output)) {
+	l.mu.RLock()
+	existing := make(map[*outputHealth]bool, len(l.outputs))
+	for _, out := range l.outputs {
+		existing[out.health] = true
+	}
+	l.mu.RUnlock()
+
+	option(l)
+
+	l.mu.Lock()
+	for i := range l.outputs {
+		if !existing[l.outputs[i].health] {
+			edit(&l.outputs[i])
+		}
+	}
+	l.mu.Unlock()
+}
+
+// variant is an entry as rendered for an output with its own view.
+type variant struct {
+	entry   *Entry
+	line    []byte
+	colored []byte
+}
+
+// variantFor renders e as seen through view, reusing the result for every
+// output sharing the view.
+func (l *Logger) variantFor(variants map[*outputView]*variant, e *Entry, view *outputView, formatter Formatter) *variant {
+	if v := variants[view]; v != nil {
+		return v
+	}
+	v := &variant{entry: e}
+	if view.filter != nil {
+		v.entry = view.filter.apply(e)
+	}
+	if view.formatter != nil {
+		formatter = view.formatter
+	}
+	text, err := formatter.Format(v.entry)
+	if err != nil {
+		l.warnf("Logger could not format entry: %v", err)
+	} else {
+		v.line = append(text, '\n')
+	}
+	variants[view] = v
+	return v
+}
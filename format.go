@@ -0,0 +1,213 @@
+package bayaan
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Formatter renders an entry as a single record, without a trailing
+// newline. Outputs add the newline and, when enabled, the level color.
+type Formatter interface {
+	Format(entry *Entry) ([]byte, error)
+}
+
+// TextFormatter is the default human-readable format: the level and
+// message on the first line, then one indented line per field.
+type TextFormatter struct {
+	TimeFormat string
+
+	// Compact writes each entry on a single line instead, for grep and
+	// other line-oriented tools:
+	//
+	//	2024-05-01 12:00:00 INFO user logged in role=admin user_id=123
+	//
+	// Fields follow in key order, groups flattened into dotted keys.
+	// Values that are empty or contain spaces, quotes, '=' or control
+	// characters are quoted. Decoder does not read this form back.
+	Compact bool
+}
+
+func (f *TextFormatter) Format(e *Entry) ([]byte, error) {
+	return f.AppendFormat(make([]byte, 0, 256), e)
+}
+
+// AppendFormat appends the text rendering of e to dst.
+func (f *TextFormatter) AppendFormat(dst []byte, e *Entry) ([]byte, error) {
+	timeFormat := f.TimeFormat
+	if timeFormat == "" {
+		timeFormat = DefaultTimeFormat
+	}
+	if f.Compact {
+		return appendCompactText(dst, e, timeFormat), nil
+	}
+	return appendText(dst, e, timeFormat), nil
+}
+
+func appendCompactText(buf []byte, e *Entry, timeFormat string) []byte {
+	buf = appendTime(buf, e.Time, timeFormat)
+	buf = append(buf, ' ')
+	buf = append(buf, e.Level.String()...)
+	buf = append(buf, ' ')
+	buf = appendCompactValue(buf, e.Message, false)
+	return appendCompactFields(buf, "", e.Fields, 0)
+}
+
+func appendCompactFields(buf []byte, prefix string, fields Fields, depth int) []byte {
+	for _, k := range sortedKeys(fields) {
+		v := fields[k]
+		if group, ok := v.(Fields); ok && depth < maxGroupDepth {
+			buf = appendCompactFields(buf, prefix+k+".", group, depth+1)
+			continue
+		}
+		buf = append(buf, ' ')
+		buf = appendCompactValue(buf, prefix+k, true)
+		buf = append(buf, '=')
+		buf = appendCompactValue(buf, sprint(v), true)
+	}
+	return buf
+}
+
+// appendCompactValue appends s, quoted when it would otherwise be ambiguous
+// or span lines. Messages only need quoting for control characters, as
+// they are not split on spaces.
+func appendCompactValue(buf []byte, s string, field bool) []byte {
+	quote := field && s == ""
+	for _, r := range s {
+		if needsEscape(r) || r == utf8.RuneError || (field && (r == ' ' || r == '\t' || r == '=' || r == '"')) {
+			quote = true
+			break
+		}
+	}
+	if quote {
+		return strconv.AppendQuote(buf, s)
+	}
+	return append(buf, s...)
+}
+
+// WithFormatter sets the format used by every writer output. WithTimeFormat
+// only applies to the default text format.
+func WithFormatter(f Formatter) LoggerOption {
+	return func(l *Logger) {
+		l.mu.Lock()
+		l.formatter = f
+		l.mu.Unlock()
+	}
+}
+
+// sprint is fmt.Sprint for field values, except that values referring to
+// themselves through maps or slices, which fmt would recurse into until the
+// stack overflows, are rendered as "<cyclic T>".
+func sprint(v interface{}) string {
+	if cyclic(reflect.ValueOf(v), 0, nil) {
+		return fmt.Sprintf("<cyclic %T>", v)
+	}
+	return fmt.Sprint(v)
+}
+
+// cyclic reports whether printing v with fmt would revisit a map or slice
+// already on the path from the root. Like fmt, it only follows pointers at
+// the top level and stops at values that format themselves.
+func cyclic(v reflect.Value, depth int, path []uintptr) bool {
+	if !v.IsValid() {
+		return false
+	}
+	if v.CanInterface() {
+		switch v.Interface().(type) {
+		case fmt.Formatter, fmt.Stringer, error:
+			return false
+		}
+	}
+	switch v.Kind() {
+	case reflect.Interface:
+		return cyclic(v.Elem(), depth, path)
+	case reflect.Pointer:
+		if depth > 0 || v.IsNil() {
+			return false
+		}
+		return cyclic(v.Elem(), depth+1, path)
+	case reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return false
+		}
+		p := v.Pointer()
+		for _, seen := range path {
+			if seen == p {
+				return true
+			}
+		}
+		path = append(path, p)
+		if v.Kind() == reflect.Slice {
+			for i := 0; i < v.Len(); i++ {
+				if cyclic(v.Index(i), depth+1, path) {
+					return true
+				}
+			}
+			return false
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			if cyclic(iter.Key(), depth+1, path) || cyclic(iter.Value(), depth+1, path) {
+				return true
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if cyclic(v.Index(i), depth+1, path) {
+				return true
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if cyclic(v.Field(i), depth+1, path) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// errorText is err.Error() that survives typed nil errors whose Error
+// method dereferences the receiver, rendering them the way fmt does.
+func errorText(err error) (s string) {
+	defer func() {
+		if recover() != nil {
+			s = fmt.Sprint(err)
+		}
+	}()
+	return err.Error()
+}
+
+// appendEscaped appends s with line breaks, escape sequences and other
+// control characters, tab aside, written as Go escapes: "\n", "\x1b",
+// "\u2028". Strings without any are appended as is.
+func appendEscaped(buf []byte, s string) []byte {
+	clean := true
+	for _, r := range s {
+		if needsEscape(r) {
+			clean = false
+			break
+		}
+	}
+	if clean {
+		return append(buf, s...)
+	}
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if needsEscape(r) {
+			quoted := strconv.QuoteRuneToASCII(r)
+			buf = append(buf, quoted[1:len(quoted)-1]...)
+		} else {
+			buf = append(buf, s[i:i+size]...)
+		}
+		i += size
+	}
+	return buf
+}
+
+// needsEscape reports whether r is a C0 or C1 control character other than
+// tab, DEL, or a Unicode line or paragraph separator.
+func needsEscape(r rune) bool {
+	return (r < 0x20 && r != '\t') || (r >= 0x7f && r <= 0x9f) || r == 0x2028 || r == 0x2029
+}
@@ -0,0 +1,87 @@
+package bayaan
+
+import (
+	"bytes"
+	"time"
+)
+
+// GCPFormatter renders each entry as JSON understood by Google Cloud
+// Logging's structured logging agents, such as those of Cloud Run, GKE and
+// App Engine:
+//
+//	{"severity":"WARNING","timestamp":"2024-05-01T12:00:00.123456789Z","message":"...",...}
+//
+// Levels map to the nearest Cloud Logging severity: TRACE and DEBUG to
+// DEBUG, WARN to WARNING, FATAL to CRITICAL and PANIC to ALERT. The
+// "trace_id" and "span_id" fields become logging.googleapis.com/trace and
+// logging.googleapis.com/spanId, so entries are linked to Cloud Trace. Other
+// fields follow in key order; those colliding with the keys above are
+// prefixed with "fields.".
+type GCPFormatter struct {
+	// ProjectID, when set, qualifies trace IDs as
+	// projects/<ProjectID>/traces/<trace_id>, the form Cloud Logging needs
+	// to link an entry to its trace.
+	ProjectID string
+
+	json JSONFormatter
+}
+
+const (
+	gcpTraceKey = "logging.googleapis.com/trace"
+	gcpSpanKey  = "logging.googleapis.com/spanId"
+)
+
+func (f *GCPFormatter) Format(e *Entry) ([]byte, error) {
+	return f.AppendFormat(nil, e)
+}
+
+// AppendFormat appends the Cloud Logging rendering of e to dst.
+func (f *GCPFormatter) AppendFormat(dst []byte, e *Entry) ([]byte, error) {
+	buf := bytes.NewBuffer(dst)
+	buf.WriteString(`{"severity":"`)
+	buf.WriteString(gcpSeverity(e.Level))
+	buf.WriteString(`","timestamp":"`)
+	buf.WriteString(e.Time.UTC().Format(time.RFC3339Nano))
+	buf.WriteString(`","message":`)
+	f.json.writeString(buf, e.Message)
+
+	for _, k := range sortedKeys(e.Fields) {
+		v := e.Fields[k]
+		name := k
+		switch k {
+		case "trace_id":
+			name = gcpTraceKey
+			if s, ok := v.(string); ok && f.ProjectID != "" {
+				v = "projects/" + f.ProjectID + "/traces/" + s
+			}
+		case "span_id":
+			name = gcpSpanKey
+		case "severity", "timestamp", "message", gcpTraceKey, gcpSpanKey:
+			name = "fields." + k
+		}
+		buf.WriteByte(',')
+		f.json.writeString(buf, name)
+		buf.WriteByte(':')
+		f.json.writeValue(buf, v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// gcpSeverity returns the Cloud Logging severity for level. Registered
+// levels take the severity of the built-in level they rank at or above.
+func gcpSeverity(level LoggerLevel) string {
+	switch {
+	case level.AtLeast(LoggerLevelPanic):
+		return "ALERT"
+	case level.AtLeast(LoggerLevelFatal):
+		return "CRITICAL"
+	case level.AtLeast(LoggerLevelError):
+		return "ERROR"
+	case level.AtLeast(LoggerLevelWarn):
+		return "WARNING"
+	case level.AtLeast(LoggerLevelInfo):
+		return "INFO"
+	}
+	return "DEBUG"
+}
@@ -0,0 +1,85 @@
+package bayaan
+
+import (
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Entry is a finished log entry as seen by hooks.
+type Entry struct {
+	Level   LoggerLevel
+	Time    time.Time
+	Message string
+	Fields  Fields
+	// Stack holds the program counters of the logging call site for
+	// ERROR and higher entries, as returned by runtime.Callers.
+	Stack []uintptr
+}
+
+func callers() []uintptr {
+	pcs := make([]uintptr, 64)
+	return pcs[:runtime.Callers(3, pcs)]
+}
+
+// Frames resolves Stack, leaving out bayaan's own frames.
+func (e *Entry) Frames() []runtime.Frame {
+	var frames []runtime.Frame
+	iter := runtime.CallersFrames(e.Stack)
+	for {
+		frame, more := iter.Next()
+		if !strings.HasPrefix(frame.Function, "github.com/ahmedsat/bayaan.") {
+			frames = append(frames, frame)
+		}
+		if !more {
+			break
+		}
+	}
+	return frames
+}
+
+// String renders the entry in the text format with the default time format.
+func (e *Entry) String() string {
+	return formatText(e, DefaultTimeFormat)
+}
+
+// Hook is invoked for every entry whose level is listed in Levels, before
+// the entry is written to the outputs. Fire may mutate the entry. A Hook
+// that implements io.Closer is closed with the logger.
+type Hook interface {
+	Levels() []LoggerLevel
+	Fire(entry *Entry) error
+}
+
+// LevelsFrom returns every level at or above min, for use in Hook.Levels.
+func LevelsFrom(min LoggerLevel) []LoggerLevel {
+	var from []LoggerLevel
+	for _, level := range Levels() {
+		if level.AtLeast(min) {
+			from = append(from, level)
+		}
+	}
+	return from
+}
+
+func WithHook(h Hook) LoggerOption {
+	return func(l *Logger) {
+		l.mu.Lock()
+		l.hooks = append(l.hooks, h)
+		l.mu.Unlock()
+	}
+}
+
+func (l *Logger) fireHooks(hooks []Hook, entry *Entry) {
+	for _, h := range hooks {
+		for _, level := range h.Levels() {
+			if level != entry.Level {
+				continue
+			}
+			if err := h.Fire(entry); err != nil {
+				l.warnf("Logger hook failed: %v", err)
+			}
+			break
+		}
+	}
+}
@@ -0,0 +1,198 @@
+// Package httplog logs net/http requests through a bayaan Logger, one entry
+// per request with its method, path, status, latency, response size, remote
+// IP and request ID:
+//
+//	handler = httplog.Middleware(logger, httplog.WithSlowThreshold(time.Second))(handler)
+package httplog
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ahmedsat/bayaan"
+)
+
+type config struct {
+	slow            time.Duration
+	requestIDHeader string
+	trustForwarded  bool
+	skip            func(*http.Request) bool
+}
+
+// Option configures Middleware.
+type Option func(*config)
+
+// WithSlowThreshold logs requests taking longer than d at WARN, with a
+// "slow" field, instead of INFO. Zero disables it.
+func WithSlowThreshold(d time.Duration) Option {
+	return func(c *config) {
+		c.slow = d
+	}
+}
+
+// WithRequestIDHeader sets the header a request ID is read from and echoed
+// in, X-Request-ID by default. Requests without one get a new ID from the
+// logger's NewID.
+func WithRequestIDHeader(name string) Option {
+	return func(c *config) {
+		c.requestIDHeader = name
+	}
+}
+
+// WithTrustForwardedFor takes the remote IP from the first X-Forwarded-For
+// address. Only enable it behind a proxy that sets the header, as clients
+// can forge it.
+func WithTrustForwardedFor() Option {
+	return func(c *config) {
+		c.trustForwarded = true
+	}
+}
+
+// WithSkip leaves requests for which skip returns true unlogged, such as
+// health checks.
+func WithSkip(skip func(*http.Request) bool) Option {
+	return func(c *config) {
+		c.skip = skip
+	}
+}
+
+// RequestID returns the ID Middleware assigned to the request ctx belongs
+// to, or "" outside of one. It is bayaan.RequestID.
+func RequestID(ctx context.Context) string {
+	return bayaan.RequestID(ctx)
+}
+
+func newConfig(options []Option) *config {
+	c := &config{requestIDHeader: "X-Request-ID"}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// withRequestID takes the request ID from the request header, or a new one
+// from the logger, echoes it in the response and stores it in the request
+// context, where the logger's *Ctx methods find it.
+func (c *config) withRequestID(logger *bayaan.Logger, w http.ResponseWriter, r *http.Request) (*http.Request, string) {
+	id := r.Header.Get(c.requestIDHeader)
+	if id == "" {
+		id = logger.NewID()
+	}
+	w.Header().Set(c.requestIDHeader, id)
+	return r.WithContext(bayaan.ContextWithRequestID(r.Context(), id)), id
+}
+
+// RequestIDMiddleware returns middleware that only assigns request IDs as
+// Middleware does, for handlers whose requests are logged elsewhere or not
+// at all. Only WithRequestIDHeader applies to it.
+func RequestIDMiddleware(logger *bayaan.Logger, options ...Option) func(http.Handler) http.Handler {
+	c := newConfig(options)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r, _ = c.withRequestID(logger, w, r)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Middleware returns middleware logging every request once it has been
+// served. Responses with a 5xx status are logged at ERROR. The request ID
+// is stored in the request context, so entries logged through the *Ctx
+// methods while serving it carry it too.
+func Middleware(logger *bayaan.Logger, options ...Option) func(http.Handler) http.Handler {
+	c := newConfig(options)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if c.skip != nil && c.skip(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			r, id := c.withRequestID(logger, w, r)
+
+			rec := &recorder{ResponseWriter: w}
+			start := time.Now()
+			next.ServeHTTP(rec, r)
+			latency := time.Since(start)
+
+			status := rec.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			fields := bayaan.Fields{
+				"method":     r.Method,
+				"path":       r.URL.Path,
+				"status":     status,
+				"latency":    latency,
+				"bytes":      rec.bytes,
+				"remote_ip":  c.remoteIP(r),
+				"request_id": id,
+			}
+
+			msg := r.Method + " " + r.URL.Path
+			switch {
+			case status >= 500:
+				logger.Error(msg, fields)
+			case c.slow > 0 && latency > c.slow:
+				fields["slow"] = true
+				logger.Warn(msg, fields)
+			default:
+				logger.Info(msg, fields)
+			}
+		})
+	}
+}
+
+func (c *config) remoteIP(r *http.Request) string {
+	if c.trustForwarded {
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			first, _, _ := strings.Cut(fwd, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// recorder captures the status and size of a response.
+type recorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *recorder) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recorder) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush keeps streaming responses working through the middleware.
+func (w *recorder) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap gives http.ResponseController access to the underlying writer,
+// for hijacking and deadlines.
+func (w *recorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
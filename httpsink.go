@@ -0,0 +1,401 @@
+package bayaan
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BatchEncoder turns a batch of entries into an HTTP request body.
+type BatchEncoder interface {
+	Encode(entries []*Entry) (body []byte, contentType string, err error)
+}
+
+// HTTPSink collects entries and POSTs them in batches, whenever the batch
+// is full or the flush interval passes. Failed requests are retried with
+// exponential backoff, and with WithSpool batches that still fail are kept
+// on disk until the endpoint is back. Requests are made on the sink's own
+// goroutine; while it is busy retrying and full batches pile up, further
+// batches are spooled or, without a spool, dropped and counted, so an
+// outage never holds up the logger.
+type HTTPSink struct {
+	url        string
+	encoder    BatchEncoder
+	batchSize  int
+	interval   time.Duration
+	maxRetries int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	headers    http.Header
+	client     *http.Client
+	spoolDir   string
+	spoolMax   int64
+
+	mu     sync.Mutex
+	batch  []*Entry
+	closed bool // guards send against Close
+
+	dropped atomic.Uint64
+
+	send chan []*Entry
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+type HTTPOption func(*HTTPSink)
+
+// WithBatch sets the batch size and the interval after which a partial
+// batch is sent anyway. The defaults are 500 entries and 5 seconds.
+func WithBatch(size int, interval time.Duration) HTTPOption {
+	return func(s *HTTPSink) {
+		s.batchSize = size
+		s.interval = interval
+	}
+}
+
+// WithRetries sets how many times a failed request is retried. The
+// default is 3.
+func WithRetries(n int) HTTPOption {
+	return func(s *HTTPSink) {
+		s.maxRetries = n
+	}
+}
+
+// WithBackoff sets the wait before the first retry, doubled for every
+// retry after it up to max. The defaults are 500ms and 30s.
+func WithBackoff(initial, max time.Duration) HTTPOption {
+	return func(s *HTTPSink) {
+		s.backoff = initial
+		s.maxBackoff = max
+	}
+}
+
+// WithSpool writes batches that could not be sent, after the retries, to
+// files in dir, and sends them again, oldest first, once the endpoint
+// responds. Spooled batches survive restarts. Beyond maxBytes of spool the
+// oldest batches are deleted. Batches the endpoint rejected with a 4xx
+// status other than 429 are not spooled, as sending them again would not
+// help.
+func WithSpool(dir string, maxBytes int64) HTTPOption {
+	return func(s *HTTPSink) {
+		s.spoolDir = dir
+		s.spoolMax = maxBytes
+	}
+}
+
+// WithHeader adds a header to every request, e.g. for authentication.
+func WithHeader(key, value string) HTTPOption {
+	return func(s *HTTPSink) {
+		s.headers.Add(key, value)
+	}
+}
+
+func NewHTTPSink(url string, encoder BatchEncoder, options ...HTTPOption) *HTTPSink {
+	s := &HTTPSink{
+		url:        url,
+		encoder:    encoder,
+		batchSize:  500,
+		interval:   5 * time.Second,
+		maxRetries: 3,
+		backoff:    500 * time.Millisecond,
+		maxBackoff: 30 * time.Second,
+		headers:    make(http.Header),
+		client:     &http.Client{Timeout: 10 * time.Second},
+		send:       make(chan []*Entry, 4),
+		stop:       make(chan struct{}),
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	s.wg.Add(1)
+	go s.run()
+	return s
+}
+
+// NewLokiSink creates an HTTPSink for Grafana Loki's push endpoint, e.g.
+// http://localhost:3100/loki/api/v1/push.
+func NewLokiSink(url string, labels map[string]string, options ...HTTPOption) *HTTPSink {
+	return NewHTTPSink(url, LokiEncoder{Labels: labels}, options...)
+}
+
+// NewElasticsearchSink creates an HTTPSink for the bulk API at url, e.g.
+// http://localhost:9200/_bulk, indexing into index.
+func NewElasticsearchSink(url, index string, options ...HTTPOption) *HTTPSink {
+	return NewHTTPSink(url, ElasticsearchEncoder{Index: index}, options...)
+}
+
+func (s *HTTPSink) WriteEntry(e *Entry) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		s.dropped.Add(1)
+		return fmt.Errorf("bayaan: HTTP sink closed, dropping entry")
+	}
+	s.batch = append(s.batch, e)
+	var full []*Entry
+	if len(s.batch) >= s.batchSize {
+		full = s.batch
+		s.batch = nil
+	}
+	s.mu.Unlock()
+
+	if full != nil {
+		return s.hand(full)
+	}
+	return nil
+}
+
+// hand passes batch to the sink's goroutine without waiting for it. When
+// the goroutine is behind, the batch is spooled, or dropped with an error.
+// Once the sink is closed, batches are dropped.
+func (s *HTTPSink) hand(batch []*Entry) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		s.dropped.Add(uint64(len(batch)))
+		return fmt.Errorf("bayaan: HTTP sink closed, dropped %d entries", len(batch))
+	}
+	select {
+	case s.send <- batch:
+		s.mu.Unlock()
+		return nil
+	default:
+	}
+	s.mu.Unlock()
+	if s.spoolDir != "" {
+		body, contentType, err := s.encoder.Encode(batch)
+		if err == nil {
+			err = s.spool(body, contentType)
+		}
+		if err == nil {
+			return nil
+		}
+		s.dropped.Add(uint64(len(batch)))
+		return fmt.Errorf("bayaan: HTTP sink behind, spooling failed, dropped %d entries: %w", len(batch), err)
+	}
+	s.dropped.Add(uint64(len(batch)))
+	return fmt.Errorf("bayaan: HTTP sink behind, dropped %d entries", len(batch))
+}
+
+// Dropped returns the number of entries dropped because the sink was
+// behind and they could not be spooled, or because it was closed.
+func (s *HTTPSink) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+func (s *HTTPSink) take() []*Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	batch := s.batch
+	s.batch = nil
+	return batch
+}
+
+func (s *HTTPSink) run() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case batch := <-s.send:
+			s.report(s.post(batch))
+		case <-ticker.C:
+			s.report(s.replay())
+			s.report(s.post(s.take()))
+		case <-s.stop:
+			s.report(s.replay())
+			for {
+				select {
+				case batch := <-s.send:
+					s.report(s.post(batch))
+				default:
+					s.report(s.post(s.take()))
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *HTTPSink) report(err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Logger HTTP sink failed: %v\n", err)
+	}
+}
+
+func (s *HTTPSink) post(batch []*Entry) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	body, contentType, err := s.encoder.Encode(batch)
+	if err != nil {
+		return err
+	}
+
+	backoff := s.backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := s.do(body, contentType)
+		if err == nil {
+			return nil
+		}
+		// Closing cuts the retries short.
+		if !retry || attempt >= s.maxRetries || !s.sleep(backoff) {
+			return s.failed(err, retry, body, contentType)
+		}
+		backoff *= 2
+		if backoff > s.maxBackoff {
+			backoff = s.maxBackoff
+		}
+	}
+}
+
+// failed spools a batch that failed for good with err, when retrying it
+// later could help.
+func (s *HTTPSink) failed(err error, retry bool, body []byte, contentType string) error {
+	if !retry || s.spoolDir == "" {
+		return err
+	}
+	if serr := s.spool(body, contentType); serr != nil {
+		return fmt.Errorf("%w; spooling the batch failed too: %v", err, serr)
+	}
+	return fmt.Errorf("%w; batch spooled to %s", err, s.spoolDir)
+}
+
+// sleep waits d, returning false early when the sink is being closed.
+func (s *HTTPSink) sleep(d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-s.stop:
+		return false
+	}
+}
+
+// do sends one request, reporting whether a failure is worth retrying.
+func (s *HTTPSink) do(body []byte, contentType string) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	for k, v := range s.headers {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("%s responded %s", s.url, resp.Status)
+}
+
+// Flush sends the current partial batch and waits for the request. A
+// batch that fails in a way worth retrying is handed to the sink's
+// goroutine to retry, so Flush does not wait out the backoff.
+func (s *HTTPSink) Flush() error {
+	batch := s.take()
+	if len(batch) == 0 {
+		return nil
+	}
+	body, contentType, err := s.encoder.Encode(batch)
+	if err != nil {
+		return err
+	}
+	retry, err := s.do(body, contentType)
+	if err != nil && retry {
+		return s.hand(batch)
+	}
+	return err
+}
+
+// Close sends everything still buffered and stops the sink. Entries
+// written afterwards are dropped with an error.
+func (s *HTTPSink) Close() error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.stop)
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return nil
+}
+
+// LokiEncoder encodes batches for Loki's push API, with one stream per
+// level carrying Labels plus a "level" label.
+type LokiEncoder struct {
+	Labels map[string]string
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+func (enc LokiEncoder) Encode(entries []*Entry) ([]byte, string, error) {
+	streams := make(map[LoggerLevel]*lokiStream)
+	var payload struct {
+		Streams []*lokiStream `json:"streams"`
+	}
+	for _, e := range entries {
+		stream := streams[e.Level]
+		if stream == nil {
+			labels := make(map[string]string, len(enc.Labels)+1)
+			for k, v := range enc.Labels {
+				labels[k] = v
+			}
+			labels["level"] = e.Level.String()
+			stream = &lokiStream{Stream: labels}
+			streams[e.Level] = stream
+			payload.Streams = append(payload.Streams, stream)
+		}
+		stream.Values = append(stream.Values, [2]string{
+			strconv.FormatInt(e.Time.UnixNano(), 10),
+			formatText(e, time.RFC3339Nano),
+		})
+	}
+	body, err := json.Marshal(payload)
+	return body, "application/json", err
+}
+
+// ElasticsearchEncoder encodes batches for the Elasticsearch bulk API,
+// each entry becoming a JSONFormatter document in Index.
+type ElasticsearchEncoder struct {
+	Index string
+}
+
+func (enc ElasticsearchEncoder) Encode(entries []*Entry) ([]byte, string, error) {
+	action, err := json.Marshal(map[string]map[string]string{"index": {"_index": enc.Index}})
+	if err != nil {
+		return nil, "", err
+	}
+	formatter := &JSONFormatter{}
+	buf := &bytes.Buffer{}
+	for _, e := range entries {
+		doc, err := formatter.Format(e)
+		if err != nil {
+			return nil, "", err
+		}
+		buf.Write(action)
+		buf.WriteByte('\n')
+		buf.Write(doc)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), "application/x-ndjson", nil
+}
@@ -0,0 +1,36 @@
+package bayaan_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ahmedsat/bayaan"
+)
+
+func TestHTTPSinkRejectsEntriesAfterClose(t *testing.T) {
+	var posts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts.Add(1)
+	}))
+	defer srv.Close()
+
+	s := bayaan.NewLokiSink(srv.URL, nil, bayaan.WithBatch(1, time.Hour))
+	entry := &bayaan.Entry{Level: bayaan.LoggerLevelInfo, Time: time.Now(), Message: "hello"}
+	if err := s.WriteEntry(entry); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+	if got := posts.Load(); got != 1 {
+		t.Errorf("posts before Close returned = %d, want 1", got)
+	}
+
+	if err := s.WriteEntry(entry); err == nil {
+		t.Error("WriteEntry after Close returned nil, want an error")
+	}
+	if got := s.Dropped(); got != 1 {
+		t.Errorf("Dropped() = %d, want 1", got)
+	}
+}
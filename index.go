@@ -0,0 +1,134 @@
+package bayaan
+
+import (
+	"bufio"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IndexSuffix is appended to a log file's path to name its error index.
+const IndexSuffix = ".idx"
+
+// IndexEntry locates one ERROR or higher entry inside a log file.
+// Entries with the same level and message share a Fingerprint.
+type IndexEntry struct {
+	Offset      int64
+	Length      int64
+	Time        time.Time
+	Level       LoggerLevel
+	Fingerprint uint64
+}
+
+type errorIndex struct {
+	file   *os.File
+	offset int64
+}
+
+func fingerprint(level LoggerLevel, msg string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(level.String()))
+	h.Write([]byte{0})
+	h.Write([]byte(msg))
+	return h.Sum64()
+}
+
+func (x *errorIndex) record(e *Entry, n int) {
+	start := x.offset
+	x.offset += int64(n)
+	if !e.Level.AtLeast(LoggerLevelError) {
+		return
+	}
+	_, _ = fmt.Fprintf(x.file, "%d %d %d %s %016x\n",
+		start, n, e.Time.UnixNano(), e.Level, fingerprint(e.Level, e.Message))
+}
+
+type indexedFile struct {
+	*os.File
+	index *os.File
+}
+
+func (f *indexedFile) Close() error {
+	f.index.Close()
+	return f.File.Close()
+}
+
+// WithIndexedFile appends entries to the file at path and maintains a
+// sidecar index of ERROR and higher entries at path+IndexSuffix.
+func WithIndexedFile(path string, additive bool) LoggerOption {
+	return func(l *Logger) {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			l.warnf("Logger could not open %s: %v", path, err)
+			return
+		}
+		idx, err := os.OpenFile(path+IndexSuffix, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			f.Close()
+			l.warnf("Logger could not open %s: %v", path+IndexSuffix, err)
+			return
+		}
+		var offset int64
+		if info, err := f.Stat(); err == nil {
+			offset = info.Size()
+		}
+
+		out := output{
+			writer: f,
+			closer: &indexedFile{File: f, index: idx},
+			index:  &errorIndex{file: idx, offset: offset},
+		}
+		l.addOutput(out, additive)
+	}
+}
+
+// ReadIndex parses the sidecar index written for the log file at path.
+func ReadIndex(path string) ([]IndexEntry, error) {
+	f, err := os.Open(path + IndexSuffix)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseIndex(f)
+}
+
+func parseIndex(r io.Reader) ([]IndexEntry, error) {
+	var entries []IndexEntry
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) != 5 {
+			continue
+		}
+		offset, err1 := strconv.ParseInt(parts[0], 10, 64)
+		length, err2 := strconv.ParseInt(parts[1], 10, 64)
+		nanos, err3 := strconv.ParseInt(parts[2], 10, 64)
+		level, err4 := ParseLevel(parts[3])
+		fp, err5 := strconv.ParseUint(parts[4], 16, 64)
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil || err5 != nil {
+			continue
+		}
+		entries = append(entries, IndexEntry{
+			Offset:      offset,
+			Length:      length,
+			Time:        time.Unix(0, nanos),
+			Level:       level,
+			Fingerprint: fp,
+		})
+	}
+	return entries, scanner.Err()
+}
+
+// ReadIndexedEntry decodes the entry an IndexEntry points at.
+func ReadIndexedEntry(path string, ie IndexEntry) (*Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return NewDecoder(io.NewSectionReader(f, ie.Offset, ie.Length)).Decode()
+}
@@ -0,0 +1,129 @@
+package bayaan
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const journaldSocket = "/run/systemd/journal/socket"
+
+// JournaldSink sends entries to systemd-journald using its native
+// protocol: the level becomes PRIORITY and every field becomes a journal
+// field, upper-cased with invalid characters replaced by underscores.
+type JournaldSink struct {
+	identifier string
+
+	mu   sync.Mutex
+	conn *net.UnixConn
+	addr *net.UnixAddr
+}
+
+// NewJournaldSink connects to the local journal. Entries are tagged with
+// SYSLOG_IDENTIFIER, which defaults to the executable name when
+// identifier is empty.
+func NewJournaldSink(identifier string) (*JournaldSink, error) {
+	if identifier == "" {
+		identifier = filepath.Base(os.Args[0])
+	}
+	addr := &net.UnixAddr{Name: journaldSocket, Net: "unixgram"}
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(journaldSocket); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("bayaan: journald is not available: %w", err)
+	}
+	return &JournaldSink{identifier: identifier, conn: conn, addr: addr}, nil
+}
+
+func journalFieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		}
+		return '_'
+	}, key)
+	name = strings.TrimLeft(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "F_" + name
+	}
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
+
+func writeJournalField(buf *bytes.Buffer, name, value string) {
+	if !strings.Contains(value, "\n") {
+		buf.WriteString(name + "=" + value + "\n")
+		return
+	}
+	// Values with newlines are sent as name, newline, little-endian
+	// 64-bit length, data, newline.
+	buf.WriteString(name + "\n")
+	binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value + "\n")
+}
+
+func (s *JournaldSink) WriteEntry(e *Entry) error {
+	buf := &bytes.Buffer{}
+	writeJournalField(buf, "MESSAGE", e.Message)
+	writeJournalField(buf, "PRIORITY", strconv.Itoa(syslogSeverity(e.Level)))
+	writeJournalField(buf, "SYSLOG_IDENTIFIER", s.identifier)
+	writeJournalField(buf, "BAYAAN_LEVEL", e.Level.String())
+
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		writeJournalField(buf, journalFieldName(k), sprint(e.Fields[k]))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return fmt.Errorf("bayaan: journald sink is closed")
+	}
+	_, err := s.conn.WriteToUnix(buf.Bytes(), s.addr)
+	if err == nil {
+		return nil
+	}
+	// Entries larger than a datagram are passed as a file descriptor.
+	return journalSendFD(s.conn, s.addr, buf.Bytes())
+}
+
+func (s *JournaldSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+func WithJournaldOutput(identifier string) LoggerOption {
+	return func(l *Logger) {
+		s, err := NewJournaldSink(identifier)
+		if err != nil {
+			l.warnf("Logger could not connect to journald: %v", err)
+			return
+		}
+		WithSink(s, true)(l)
+	}
+}
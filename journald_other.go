@@ -0,0 +1,12 @@
+//go:build !unix
+
+package bayaan
+
+import (
+	"errors"
+	"net"
+)
+
+func journalSendFD(conn *net.UnixConn, addr *net.UnixAddr, data []byte) error {
+	return errors.New("bayaan: entry too large for journald")
+}
@@ -0,0 +1,24 @@
+//go:build unix
+
+package bayaan
+
+import (
+	"net"
+	"os"
+	"syscall"
+)
+
+func journalSendFD(conn *net.UnixConn, addr *net.UnixAddr, data []byte) error {
+	f, err := os.CreateTemp("/dev/shm", "bayaan-journal-")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	_, _, err = conn.WriteMsgUnix(nil, syscall.UnixRights(int(f.Fd())), addr)
+	return err
+}
@@ -0,0 +1,247 @@
+package bayaan
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSONFormatter renders each entry as a single JSON object with "time",
+// "level" and "msg" keys followed by the fields in key order. Fields that
+// collide with those keys are prefixed with "fields.".
+type JSONFormatter struct {
+	// TimeFormat defaults to time.RFC3339Nano.
+	TimeFormat string
+
+	// IntsAsStrings encodes 64-bit integers and big.Int values as JSON
+	// strings, so JavaScript consumers don't silently lose precision
+	// past 2^53.
+	IntsAsStrings bool
+
+	// FloatDecimals, when positive, writes floats with exactly that many
+	// decimal places. Zero keeps the shortest exact representation.
+	FloatDecimals int
+
+	// TimeKey, LevelKey and MessageKey rename the "time", "level" and
+	// "msg" keys; ErrorKey renames the "error" field. Empty keeps the
+	// default name. There is no caller key: the logger does not record
+	// the caller, so a "caller" field is only written when one is given,
+	// under that name.
+	TimeKey    string
+	LevelKey   string
+	MessageKey string
+	ErrorKey   string
+
+	// LevelEncoding selects how the level is written, LevelUppercase by
+	// default.
+	LevelEncoding LevelEncoding
+}
+
+// LevelEncoding is how JSONFormatter writes levels.
+type LevelEncoding int
+
+const (
+	// LevelUppercase writes the level name as is: "INFO".
+	LevelUppercase LevelEncoding = iota
+	// LevelLowercase writes the level name in lower case: "info".
+	LevelLowercase
+	// LevelNumeric writes the level's LoggerLevel value: 2.
+	LevelNumeric
+)
+
+func (f *JSONFormatter) Format(e *Entry) ([]byte, error) {
+	return f.AppendFormat(nil, e)
+}
+
+// AppendFormat appends the JSON rendering of e to dst.
+func (f *JSONFormatter) AppendFormat(dst []byte, e *Entry) ([]byte, error) {
+	timeFormat := f.TimeFormat
+	if timeFormat == "" {
+		timeFormat = time.RFC3339Nano
+	}
+	timeKey := keyOr(f.TimeKey, "time")
+	levelKey := keyOr(f.LevelKey, "level")
+	msgKey := keyOr(f.MessageKey, "msg")
+
+	buf := bytes.NewBuffer(dst)
+	buf.WriteByte('{')
+	f.writeString(buf, timeKey)
+	buf.WriteByte(':')
+	if n, ok := epoch(e.Time, timeFormat); ok {
+		buf.WriteString(strconv.FormatInt(n, 10))
+	} else {
+		f.writeString(buf, e.Time.Format(timeFormat))
+	}
+	buf.WriteByte(',')
+	f.writeString(buf, levelKey)
+	buf.WriteByte(':')
+	switch f.LevelEncoding {
+	case LevelLowercase:
+		f.writeString(buf, strings.ToLower(e.Level.String()))
+	case LevelNumeric:
+		buf.WriteString(strconv.Itoa(int(e.Level)))
+	default:
+		f.writeString(buf, e.Level.String())
+	}
+	buf.WriteByte(',')
+	f.writeString(buf, msgKey)
+	buf.WriteByte(':')
+	f.writeString(buf, e.Message)
+
+	for _, k := range sortedKeys(e.Fields) {
+		name := k
+		if k == "error" && f.ErrorKey != "" {
+			name = f.ErrorKey
+		}
+		if name == timeKey || name == levelKey || name == msgKey {
+			name = "fields." + name
+		}
+		buf.WriteByte(',')
+		f.writeString(buf, name)
+		buf.WriteByte(':')
+		f.writeValue(buf, e.Fields[k])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func keyOr(key, def string) string {
+	if key == "" {
+		return def
+	}
+	return key
+}
+
+func sortedKeys(fields Fields) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// writeGroup writes a group as a nested object, encoding its values like
+// top-level fields.
+func (f *JSONFormatter) writeGroup(buf *bytes.Buffer, group Fields, depth int) {
+	buf.WriteByte('{')
+	for i, k := range sortedKeys(group) {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		f.writeString(buf, k)
+		buf.WriteByte(':')
+		f.writeValueDepth(buf, group[k], depth)
+	}
+	buf.WriteByte('}')
+}
+
+func (f *JSONFormatter) writeString(buf *bytes.Buffer, s string) {
+	b, _ := json.Marshal(s)
+	buf.Write(b)
+}
+
+func (f *JSONFormatter) writeInt(buf *bytes.Buffer, digits string) {
+	if f.IntsAsStrings {
+		buf.WriteByte('"')
+		buf.WriteString(digits)
+		buf.WriteByte('"')
+		return
+	}
+	buf.WriteString(digits)
+}
+
+func (f *JSONFormatter) writeFloat(buf *bytes.Buffer, v float64, bitSize int) {
+	switch {
+	case math.IsNaN(v):
+		buf.WriteString(`"NaN"`)
+	case math.IsInf(v, 1):
+		buf.WriteString(`"+Inf"`)
+	case math.IsInf(v, -1):
+		buf.WriteString(`"-Inf"`)
+	case f.FloatDecimals > 0:
+		buf.WriteString(strconv.FormatFloat(v, 'f', f.FloatDecimals, bitSize))
+	default:
+		// Same cutoffs as encoding/json for switching to exponent form.
+		format := byte('f')
+		if abs := math.Abs(v); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+			format = 'e'
+		}
+		buf.WriteString(strconv.FormatFloat(v, format, -1, bitSize))
+	}
+}
+
+func (f *JSONFormatter) writeValue(buf *bytes.Buffer, v interface{}) {
+	f.writeValueDepth(buf, v, 0)
+}
+
+// writeValueDepth writes v, depth being the number of groups it is nested
+// in.
+func (f *JSONFormatter) writeValueDepth(buf *bytes.Buffer, v interface{}, depth int) {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case Fields:
+		if v == nil {
+			buf.WriteString("null")
+			return
+		}
+		if depth >= maxGroupDepth {
+			f.writeString(buf, sprint(v))
+			return
+		}
+		f.writeGroup(buf, v, depth+1)
+	case string:
+		f.writeString(buf, v)
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case int:
+		f.writeInt(buf, strconv.FormatInt(int64(v), 10))
+	case int64:
+		f.writeInt(buf, strconv.FormatInt(v, 10))
+	case uint:
+		f.writeInt(buf, strconv.FormatUint(uint64(v), 10))
+	case uint64:
+		f.writeInt(buf, strconv.FormatUint(v, 10))
+	case int8, int16, int32, uint8, uint16, uint32:
+		fmt.Fprint(buf, v)
+	case float32:
+		f.writeFloat(buf, float64(v), 32)
+	case float64:
+		f.writeFloat(buf, v, 64)
+	case *big.Int:
+		if v == nil {
+			buf.WriteString("null")
+			return
+		}
+		f.writeInt(buf, v.String())
+	case big.Int:
+		f.writeInt(buf, v.String())
+	case time.Time:
+		f.writeString(buf, v.Format(time.RFC3339Nano))
+	case time.Duration:
+		f.writeString(buf, v.String())
+	case error:
+		f.writeString(buf, errorText(v))
+	case json.Marshaler:
+		b, err := v.MarshalJSON()
+		if err != nil || !json.Valid(b) {
+			f.writeString(buf, sprint(v))
+			return
+		}
+		buf.Write(b)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			f.writeString(buf, sprint(v))
+			return
+		}
+		buf.Write(b)
+	}
+}
@@ -0,0 +1,155 @@
+package bayaan
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// customLevelBase is the value of the first registered level, clear of the
+// built-in ones.
+const customLevelBase LoggerLevel = 100
+
+// levelTable orders the built-in and registered levels. It is replaced, not
+// modified, on registration.
+type levelTable struct {
+	order  []LoggerLevel // least severe first
+	rank   map[LoggerLevel]int
+	names  map[LoggerLevel]string
+	colors map[LoggerLevel]string
+}
+
+var (
+	levelsMu sync.Mutex
+	levels   atomic.Pointer[levelTable] // nil until a level is registered
+)
+
+// RegisterLevel adds a level named name, ranked directly above the level
+// above and colored with the given ANSI sequence, and returns it. Register
+// levels during initialization:
+//
+//	var LevelNotice = bayaan.RegisterLevel("NOTICE", bayaan.LoggerLevelInfo, bayaan.Color256(45))
+//
+// The new level filters, samples and colors like any other, and is logged
+// with Log. RegisterLevel panics if the name is taken or above is unknown.
+func RegisterLevel(name string, above LoggerLevel, color string) LoggerLevel {
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	if name == "" || strings.ContainsAny(name, " \t\r\n") {
+		panic(fmt.Sprintf("bayaan: invalid level name %q", name))
+	}
+	if _, err := ParseLevel(name); err == nil {
+		panic(fmt.Sprintf("bayaan: level %q already exists", name))
+	}
+
+	old := levels.Load()
+	t := &levelTable{
+		rank:   make(map[LoggerLevel]int),
+		names:  make(map[LoggerLevel]string),
+		colors: make(map[LoggerLevel]string),
+	}
+	if old == nil {
+		for level := LoggerLevelTrace; level < LoggerLevelsCount; level++ {
+			t.order = append(t.order, level)
+		}
+	} else {
+		t.order = append(t.order, old.order...)
+		for level, n := range old.names {
+			t.names[level] = n
+			t.colors[level] = old.colors[level]
+		}
+	}
+
+	at := -1
+	for i, level := range t.order {
+		if level == above {
+			at = i + 1
+		}
+	}
+	if at < 0 {
+		panic(fmt.Sprintf("bayaan: cannot register %q above unknown level %v", name, above))
+	}
+
+	level := customLevelBase + LoggerLevel(len(t.names))
+	t.order = append(t.order[:at], append([]LoggerLevel{level}, t.order[at:]...)...)
+	for i, l := range t.order {
+		t.rank[l] = i
+	}
+	t.names[level] = name
+	t.colors[level] = color
+	levels.Store(t)
+	return level
+}
+
+// Levels returns every built-in and registered level, least severe first.
+func Levels() []LoggerLevel {
+	if t := levels.Load(); t != nil {
+		return append([]LoggerLevel(nil), t.order...)
+	}
+	order := make([]LoggerLevel, 0, LoggerLevelsCount)
+	for level := LoggerLevelTrace; level < LoggerLevelsCount; level++ {
+		order = append(order, level)
+	}
+	return order
+}
+
+// AtLeast reports whether l is as severe as min or more, taking registered
+// levels into account. Compare levels with it rather than with < and >=.
+// Unknown levels rank below every level when negative, above otherwise.
+func (l LoggerLevel) AtLeast(min LoggerLevel) bool {
+	t := levels.Load()
+	if t == nil {
+		return l >= min
+	}
+	return t.rankOf(l) >= t.rankOf(min)
+}
+
+func (t *levelTable) rankOf(l LoggerLevel) int {
+	if r, ok := t.rank[l]; ok {
+		return r
+	}
+	if l < 0 {
+		return int(l) - len(t.order)
+	}
+	return int(l) + len(t.order) + int(customLevelBase)
+}
+
+// customName returns the name of a registered level.
+func (l LoggerLevel) customName() (string, bool) {
+	t := levels.Load()
+	if t == nil {
+		return "", false
+	}
+	name, ok := t.names[l]
+	return name, ok
+}
+
+// color returns the default ANSI sequence for l.
+func (l LoggerLevel) color() string {
+	if c, ok := colors[l]; ok {
+		return c
+	}
+	if t := levels.Load(); t != nil {
+		return t.colors[l]
+	}
+	return ""
+}
+
+// unknownLevel renders levels that are neither built in nor registered.
+func unknownLevel(l LoggerLevel) string {
+	return "LEVEL(" + strconv.Itoa(int(l)) + ")"
+}
+
+// Log logs msg at level, which may be a registered level. Unlike Fatal and
+// Panic it never exits or panics, whatever the level.
+func (l *Logger) Log(level LoggerLevel, msg string, fields Fields) {
+	l.log(level, msg, fields)
+}
+
+// Log logs msg at level through the default logger.
+func Log(level LoggerLevel, msg string, fields Fields) {
+	defaultLogger.Log(level, msg, fields)
+}
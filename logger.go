@@ -1,12 +1,14 @@
 package bayaan
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -41,9 +43,31 @@ func (l LoggerLevel) String() string {
 		len(levels) == int(LoggerLevelsCount): true,
 	}
 
+	if l < 0 || int(l) >= len(levels) {
+		if name, ok := l.customName(); ok {
+			return name
+		}
+		return unknownLevel(l)
+	}
 	return levels[l]
 }
 
+func ParseLevel(s string) (LoggerLevel, error) {
+	for l := LoggerLevelTrace; l < LoggerLevelsCount; l++ {
+		if strings.EqualFold(s, l.String()) {
+			return l, nil
+		}
+	}
+	if t := levels.Load(); t != nil {
+		for l, name := range t.names {
+			if strings.EqualFold(s, name) {
+				return l, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("bayaan: unknown level %q", s)
+}
+
 var colors = map[LoggerLevel]string{
 	LoggerLevelTrace: "\033[36m", // Cyan
 	LoggerLevelDebug: "\033[34m", // Blue
@@ -56,24 +80,76 @@ var colors = map[LoggerLevel]string{
 
 const Reset = "\033[0m"
 
+const DefaultTimeFormat = "2006-01-02 15:04:05"
+
 type logEntry struct {
 	level  LoggerLevel
 	msg    string
 	fields Fields
+	typed  []Field
+	time   time.Time
+	stack  []uintptr
+	done   chan struct{}
+	flush  bool
+	// control, when set, runs on the writer in place of writing an entry.
+	control func()
+	// logger is the Logger the entry was logged through, whose default
+	// fields apply. It may be a child of the one writing the entry.
+	logger *Logger
 }
 
 type output struct {
+	sink     Sink
 	writer   io.Writer
 	useColor bool
+	terminal bool // the writer is a terminal and NO_COLOR is unset; see ColorAuto
+	closer   io.Closer
+	index    *errorIndex
+	health   *outputHealth
+	view     *outputView       // per-output fields and format, nil for the shared rendering
+	failover *failover         // set on backup outputs; see WithFailover
+	accept   func(*Entry) bool // the entries the output receives, nil for all
 }
 
 type Logger struct {
-	level      LoggerLevel
+	level      *levelVar   // falls back to the level of the logger l was derived from
+	live       *liveFields // set with SetField, over those of the logger l was derived from
 	outputs    []output
 	timeFormat string
+	location   *time.Location
+	formatter  Formatter
+	bytesEnc   bytesEncoding
+	limits     sizeLimits
+	filters    []func(Entry) bool
 	mu         sync.RWMutex
 	fields     Fields
+	hooks      []Hook
+	borrowed   int // leading hooks copied by Clone, closed by the logger they came from
+	samplers   map[LoggerLevel]*sampler
+	msgSampler *messageSampler
+	limiters   []*rateLimiter
+	dedup      *dedup
+	redact     map[string]bool
+	ring       *ring
+	exitCodes  *exitCodes
+	exitFunc   func(int)
+	onFatal    []func(code int)
+	fatal      *deferredFatal
+	stops      []func()
+	timeout    time.Duration
+	color      ColorMode
+	theme      *Theme
+	tracer     TraceExtractor
+	clock      Clock
+	ids        IDGenerator
+	entryIDs   *ULIDGenerator
+	diag       io.Writer
+	onDrop     func(Entry)
+	onError    func(output string, err error)
+	state      *writerState
 	logChan    chan logEntry
+	writers    int            // WithWriters
+	workers    *outputWorkers // nil when the writer goroutine writes outputs itself
 	done       chan struct{}
 }
 
@@ -83,21 +159,54 @@ type LoggerOption func(*Logger)
 
 func NewLogger(options ...LoggerOption) *Logger {
 	l := &Logger{
-		level:      LoggerLevelInfo,
-		outputs:    []output{{writer: os.Stdout, useColor: true}},
-		timeFormat: "2006-01-02 15:04:05",
+		level:      newLevelVar(LoggerLevelInfo),
+		live:       &liveFields{},
+		outputs:    []output{{writer: os.Stdout, useColor: true, terminal: colorTerminal(os.Stdout), health: &outputHealth{}}},
+		timeFormat: DefaultTimeFormat,
+		bytesEnc:   defaultBytesEncoding,
 		fields:     make(Fields),
-		logChan:    make(chan logEntry, 1000), // Buffered channel to prevent blocking
+		logChan:    make(chan logEntry, defaultQueueSize+urgentCapacity), // Buffered channel to prevent blocking
+		clock:      systemClock{},
+		ids:        randomIDs{},
+		state:      &writerState{quit: make(chan struct{})},
 		done:       make(chan struct{}),
 	}
 
+	l.state.owner = l
+
 	for _, option := range options {
 		option(l)
 	}
+	if l.writers > 1 && !l.state.inline {
+		l.workers = startOutputWorkers(l.writers, cap(l.logChan))
+	}
 
 	go func() {
-		for entry := range l.logChan {
+		l.state.goroutine.Store(goroutineID())
+		write := func(entry logEntry) {
+			l.state.busySince.Store(time.Now().UnixNano())
 			l.writeLog(entry)
+			l.state.busySince.Store(0)
+		}
+		for running := true; running; {
+			select {
+			case entry := <-l.logChan:
+				write(entry)
+			case <-l.state.quit:
+				running = false
+			}
+		}
+		// Write what was queued before Close.
+		for len(l.logChan) > 0 {
+			write(<-l.logChan)
+		}
+		if l.dedup != nil {
+			if summary := l.dedup.flush(); summary != nil {
+				l.write(*summary)
+			}
+		}
+		if l.workers != nil {
+			l.workers.stop()
 		}
 		close(l.done)
 	}()
@@ -107,21 +216,93 @@ func NewLogger(options ...LoggerOption) *Logger {
 
 func WithLevel(level LoggerLevel) LoggerOption {
 	return func(l *Logger) {
-		l.mu.Lock()
-		l.level = level
-		l.mu.Unlock()
+		l.setLevel(level)
+	}
+}
+
+// SetLevel changes the minimum level of a live logger, without touching
+// its outputs or fields. Entries still queued are checked against the new
+// level when written. Loggers derived from l with With and the like follow
+// its level, including later changes, until SetLevel gives them their own.
+func (l *Logger) SetLevel(level LoggerLevel) {
+	l.setLevel(level)
+}
+
+func (l *Logger) setLevel(level LoggerLevel) {
+	l.level.store(level)
+}
+
+// Level returns the logger's minimum level.
+func (l *Logger) Level() LoggerLevel {
+	return l.minLevel()
+}
+
+// minLevel returns the level in effect for l: its own, or else that of the
+// closest logger it was derived from with a level of its own.
+func (l *Logger) minLevel() LoggerLevel {
+	return l.level.load()
+}
+
+// levelVar is a level that can change while loggers read it. Until a level
+// is stored in it, it reads as its parent.
+type levelVar struct {
+	v      atomic.Int64
+	set    atomic.Bool
+	parent *levelVar
+}
+
+func newLevelVar(level LoggerLevel) *levelVar {
+	v := &levelVar{}
+	v.store(level)
+	return v
+}
+
+// child returns an unset variable reading as v.
+func (v *levelVar) child() *levelVar {
+	return &levelVar{parent: v}
+}
+
+func (v *levelVar) load() LoggerLevel {
+	for !v.set.Load() && v.parent != nil {
+		v = v.parent
 	}
+	return LoggerLevel(v.v.Load())
+}
+
+func (v *levelVar) store(level LoggerLevel) {
+	v.v.Store(int64(level))
+	v.set.Store(true)
 }
 
 func WithOutput(writer io.Writer, additive bool, useColor bool) LoggerOption {
 	return func(l *Logger) {
-		l.mu.Lock()
-		if additive {
-			l.outputs = append(l.outputs, output{writer: writer, useColor: useColor})
-		} else {
-			l.outputs = []output{{writer: writer, useColor: useColor}}
+		l.addOutput(output{writer: writer, useColor: useColor}, additive)
+	}
+}
+
+// addOutput registers out, replacing the existing outputs unless additive.
+func (l *Logger) addOutput(out output, additive bool) {
+	out.health = &outputHealth{}
+	out.terminal = out.useColor && colorTerminal(out.writer)
+	var dropped []output
+	l.mu.Lock()
+	if additive {
+		l.outputs = append(l.outputs, out)
+	} else {
+		dropped = l.outputs
+		l.outputs = []output{out}
+	}
+	l.mu.Unlock()
+	closeOutputs(dropped)
+}
+
+// closeOutputs closes the outputs the logger opened itself among outs,
+// which have been removed from it.
+func closeOutputs(outs []output) {
+	for _, out := range outs {
+		if out.closer != nil {
+			_ = out.closer.Close()
 		}
-		l.mu.Unlock()
 	}
 }
 
@@ -133,6 +314,23 @@ func WithTimeFormat(format string) LoggerOption {
 	}
 }
 
+// WithLocation renders entry timestamps in loc rather than the clock's
+// location, which for the system clock is the server's local time zone.
+// Sinks receive the converted time too.
+func WithLocation(loc *time.Location) LoggerOption {
+	return func(l *Logger) {
+		l.mu.Lock()
+		l.location = loc
+		l.mu.Unlock()
+	}
+}
+
+// WithUTC renders entry timestamps in UTC, so fleets spread across time
+// zones log comparable times. It is WithLocation(time.UTC).
+func WithUTC() LoggerOption {
+	return WithLocation(time.UTC)
+}
+
 func WithFields(fields Fields) LoggerOption {
 	return func(l *Logger) {
 		l.mu.Lock()
@@ -144,71 +342,538 @@ func WithFields(fields Fields) LoggerOption {
 }
 
 func (l *Logger) writeLog(entry logEntry) {
-	if entry.level < l.level {
+	if entry.done != nil {
+		defer close(entry.done)
+	}
+	if (entry.flush || entry.control != nil) && l.workers != nil {
+		l.workers.wait()
+	}
+	if entry.flush {
+		l.flushOutputs()
+		return
+	}
+	if entry.control != nil {
+		entry.control()
+		return
+	}
+	if l.buffer(entry) {
+		return
+	}
+	source := entry.logger
+	if source == nil {
+		source = l
+	}
+	if !entry.level.AtLeast(source.Level()) {
 		return
 	}
 
-	l.mu.RLock()
-	defaultFields := make(Fields, len(l.fields))
-	for k, v := range l.fields {
-		defaultFields[k] = v
+	if l.dedup != nil {
+		suppress, summary := l.dedup.check(entry, l.now())
+		if summary != nil {
+			l.write(*summary)
+		}
+		if suppress {
+			return
+		}
+	}
+
+	l.write(entry)
+}
+
+func (l *Logger) write(entry logEntry) {
+	source := entry.logger
+	if source == nil {
+		source = l
 	}
+	source.mu.RLock()
+	fields := make(Fields, len(source.fields)+len(entry.fields))
+	for k, v := range source.fields {
+		fields[k] = v
+	}
+	source.mu.RUnlock()
+	source.live.addTo(fields)
+
+	l.mu.RLock()
 	outputs := make([]output, len(l.outputs))
 	copy(outputs, l.outputs)
+	hooks := make([]Hook, len(l.hooks))
+	copy(hooks, l.hooks)
+	formatter := l.formatter
+	if formatter == nil {
+		formatter = &TextFormatter{TimeFormat: l.timeFormat}
+	}
+	bytesEnc := l.bytesEnc
+	limits := l.limits
+	timeout := l.timeout
+	redactKeys := l.redact
+	colorMode := l.color
+	theme := l.theme
+	location := l.location
+	entryIDs := l.entryIDs
+	filters := l.filters
 	l.mu.RUnlock()
 
-	space := make([]byte, len(entry.level.String())+2)
-	// fill space with spaces
-	for i := range space {
-		space[i] = ' '
+	for k, v := range entry.fields {
+		fields[k] = v
+	}
+	for _, f := range entry.typed {
+		fields[f.Key] = f.Value()
+	}
+	for k, v := range fields {
+		v = redact(k, encodeValue(resolve(v)), redactKeys, 0)
+		if p, ok := v.([]byte); ok {
+			v = bytesEnc.render(p)
+		}
+		fields[k] = v
 	}
-	space = append([]byte{'\n'}, space...)
+	limits.apply(&entry.msg, fields)
 
-	output := &strings.Builder{}
-	output.WriteString(entry.level.String() + ": ")
-	output.WriteString(entry.msg)
-	output.Write(space)
-	output.WriteString("time: " + time.Now().Format(l.timeFormat))
-	for k, v := range defaultFields {
-		output.Write(space)
-		output.WriteString(fmt.Sprintf("%s: %v ", k, v))
+	if entry.time.IsZero() {
+		entry.time = l.now()
 	}
-	for k, v := range entry.fields {
-		output.Write(space)
-		output.WriteString(fmt.Sprintf("%s: %v ", k, v))
+	if location != nil {
+		entry.time = entry.time.In(location)
+	}
+	if entryIDs != nil {
+		fields["entry_id"] = entryIDs.next(entry.time)
+	}
+	e := &Entry{
+		Level:   entry.level,
+		Time:    entry.time,
+		Message: entry.msg,
+		Fields:  fields,
+		Stack:   entry.stack,
 	}
+	for _, keep := range filters {
+		if !keep(*e) {
+			return
+		}
+	}
+	l.fireHooks(hooks, e)
+	l.state.countLevel(e.Level)
 
-	for _, out := range outputs {
-		logLine := output.String() + "\n"
-		if out.useColor {
-			logLine = colors[entry.level] + logLine + Reset
+	lineBuf, colorBuf := getBuffer(), getBuffer()
+	var text []byte
+	var err error
+	if af, ok := formatter.(appendFormatter); ok {
+		text, err = af.AppendFormat(*lineBuf, e)
+	} else {
+		text, err = formatter.Format(e)
+	}
+	if err != nil {
+		l.warnf("Logger could not format entry: %v", err)
+		return
+	}
+
+	// Every output shares the same rendered bytes; the newline and the color
+	// codes are added once per entry rather than once per output.
+	line := append(text, '\n')
+	var colored []byte
+	var variants map[*outputView]*variant
+	// Buffers still referenced by a write are left to the garbage collector
+	// rather than reused: a write abandoned on timeout, or one queued on an
+	// output worker.
+	abandoned := l.workers != nil
+	for i, out := range outputs {
+		if out.accept != nil && !out.accept(e) {
+			continue
+		}
+		if l.standby(out) {
+			continue
+		}
+		ent, p := e, line
+		var v *variant
+		if out.view != nil {
+			if variants == nil {
+				variants = make(map[*outputView]*variant)
+			}
+			v = l.variantFor(variants, e, out.view, formatter)
+			ent, p = v.entry, v.line
+		}
+		if out.sink != nil {
+			if l.workers != nil {
+				l.submit(i, out, func() { l.writeSink(out, ent, timeout) })
+			} else {
+				l.writeSink(out, ent, timeout)
+			}
+			continue
+		}
+		if p == nil {
+			continue // the filtered variant failed to format
+		}
+		useColor := colorMode.colorize(out)
+		if useColor && v != nil {
+			if v.colored == nil {
+				v.colored = theme.paint(nil, e.Level, p)
+			}
+			p = v.colored
+		} else if useColor {
+			if colored == nil {
+				colored = theme.paint(*colorBuf, e.Level, line)
+			}
+			p = colored
+		}
+		if l.workers != nil {
+			l.submit(i, out, func() { l.writeOutput(out, ent, p, timeout) })
+		} else if l.writeOutput(out, ent, p, timeout) {
+			abandoned = true
 		}
-		_, _ = fmt.Fprint(out.writer, logLine)
 	}
+	l.degrade(e, line, outputs)
+
+	if !abandoned {
+		if colored == nil {
+			colored = *colorBuf
+		}
+		putBuffer(lineBuf, line)
+		putBuffer(colorBuf, colored)
+	}
+}
+
+// writeSink hands ent to a sink output.
+func (l *Logger) writeSink(out output, ent *Entry, timeout time.Duration) {
+	_, err := deliver(out, timeout, func() (int, error) {
+		return 0, out.sink.WriteEntry(ent)
+	})
+	if err != nil && err != errOutputBusy {
+		l.outputFailed(out, err)
+	}
+	out.health.report(err)
+}
+
+// writeOutput writes the rendering p of ent to a writer output, reporting
+// whether the write was abandoned on timeout and may still use p.
+func (l *Logger) writeOutput(out output, ent *Entry, p []byte, timeout time.Duration) bool {
+	n, err := deliver(out, timeout, func() (int, error) {
+		return out.writer.Write(p)
+	})
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		l.warnf("Logger output write timed out after %s, dropping entries until it returns", timeout)
+	}
+	if err != nil && err != errOutputBusy {
+		l.outputFailed(out, err)
+	}
+	if out.index != nil {
+		out.index.record(ent, n)
+	}
+	out.health.report(err)
+	return err == errWriteAbandoned
+}
+
+func formatText(e *Entry, timeFormat string) string {
+	return string(appendText(nil, e, timeFormat))
+}
+
+// appendText appends the default text rendering of e to buf. Control
+// characters in the message, keys and values are escaped, so logged input
+// cannot start lines that pass for other entries or drive the terminal.
+func appendText(buf []byte, e *Entry, timeFormat string) []byte {
+	level := e.Level.String()
+	// Continuation lines are indented to line up with the message.
+	indent := make([]byte, 0, len(level)+3)
+	indent = append(indent, '\n')
+	for i := 0; i < len(level)+2; i++ {
+		indent = append(indent, ' ')
+	}
+
+	buf = append(buf, level...)
+	buf = append(buf, ": "...)
+	buf = appendEscaped(buf, e.Message)
+	buf = append(buf, indent...)
+	buf = append(buf, "time: "...)
+	buf = appendTime(buf, e.Time, timeFormat)
+	return appendTextFields(buf, indent, "", e.Fields, 0)
 }
 
+// maxGroupDepth bounds group nesting, so a group containing itself is
+// printed (as cyclic) instead of recursed into forever.
+const maxGroupDepth = 16
+
+// appendTextFields appends one line per field, flattening groups into
+// dotted keys.
+func appendTextFields(buf, indent []byte, prefix string, fields Fields, depth int) []byte {
+	for k, v := range fields {
+		if group, ok := v.(Fields); ok && depth < maxGroupDepth {
+			buf = appendTextFields(buf, indent, prefix+k+".", group, depth+1)
+			continue
+		}
+		buf = append(buf, indent...)
+		buf = appendEscaped(buf, prefix)
+		buf = appendEscaped(buf, k)
+		buf = append(buf, ": "...)
+		buf = appendEscaped(buf, sprint(v))
+		buf = append(buf, ' ')
+	}
+	return buf
+}
+
+// Close writes the queued entries, then closes the outputs and hooks. It
+// is shared with every derived logger: closing any of them closes the
+// logger NewLogger built, and calls after the first do nothing. Entries
+// logged after Close go straight to stderr, in the text format, as when
+// the writer is stalled. Close waits for the outputs however long they
+// take; CloseWithTimeout bounds the wait.
 func (l *Logger) Close() {
+	l.state.closeOnce.Do(l.state.owner.close)
+}
+
+// CloseContext is Close giving up on a wedged output: if ctx is done
+// before the queue is written and the outputs closed, it returns an error
+// wrapping ctx.Err() and the number of entries still queued, leaving Close
+// to finish in the background. Entries logged afterwards go to stderr.
+func (l *Logger) CloseContext(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		l.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("bayaan: close abandoned with %d entries queued: %w", len(l.logChan), ctx.Err())
+	}
+}
+
+// CloseWithTimeout is CloseContext with a deadline d from now.
+func (l *Logger) CloseWithTimeout(d time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return l.CloseContext(ctx)
+}
+
+func (l *Logger) close() {
+	l.mu.RLock()
+	stops := l.stops
+	l.mu.RUnlock()
+	// Stop background producers before the queue closes under them.
+	for _, stop := range stops {
+		stop()
+	}
 
-	close(l.logChan)
-	<-l.done
+	l.mu.RLock()
+	for _, r := range l.limiters {
+		r.stop()
+	}
+	l.mu.RUnlock()
+
+	l.state.sending.Lock()
+	l.state.closed.Store(true)
+	l.state.sending.Unlock()
+	close(l.state.quit)
+	<-l.state.owner.done
+
+	l.mu.RLock()
+	for _, out := range l.outputs {
+		if out.closer != nil {
+			_ = out.closer.Close()
+		}
+	}
+	for _, h := range l.hooks[l.borrowed:] {
+		if c, ok := h.(io.Closer); ok {
+			_ = c.Close()
+		}
+	}
+	l.mu.RUnlock()
 }
 
 func (l *Logger) log(level LoggerLevel, msg string, fields Fields) {
+	l.emit(logEntry{level: level, msg: msg, fields: fields, logger: l})
+}
+
+// enabled reports whether an entry at level would be kept: it passes the
+// logger's level, or it is debug chatter for the ring buffer.
+func (l *Logger) enabled(level LoggerLevel) bool {
+	if level.AtLeast(l.minLevel()) {
+		return true
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.ring != nil && LoggerLevelDebug.AtLeast(level)
+}
+
+// emit applies the level check, sampling and rate limiting to a new entry
+// and queues it. Entries below the level are discarded here, before they
+// take up space in the queue.
+func (l *Logger) emit(entry logEntry) {
+	min := l.minLevel()
+	l.mu.RLock()
+	if !entry.level.AtLeast(min) && (l.ring == nil || !LoggerLevelDebug.AtLeast(entry.level)) {
+		l.mu.RUnlock()
+		return
+	}
+	s := l.samplers[entry.level]
+	ms := l.msgSampler
+	limiters := l.limiters
+	l.mu.RUnlock()
+	now := l.now()
+	if s != nil && !s.sample(now) {
+		return
+	}
+	if ms != nil && !ms.sample(now, entry.level, entry.msg) {
+		return
+	}
+	for _, r := range limiters {
+		if !r.allow(now, entry.level, entry.msg, entry.fields) {
+			return
+		}
+	}
+
+	if entry.level.AtLeast(LoggerLevelError) {
+		entry.stack = callers()
+	}
+	l.send(entry)
+}
+
+func (l *Logger) enqueue(level LoggerLevel, msg string, fields Fields) {
+	l.send(logEntry{level: level, msg: msg, fields: fields, logger: l})
+}
+
+// urgentCapacity is the number of queue slots reserved for ERROR and above,
+// so under overload it is lower-level chatter that gets dropped. Entries
+// share one queue either way and are written in order.
+const urgentCapacity = 100
+
+func (l *Logger) send(entry logEntry) {
+	var queued bool
+	switch {
+	case l.state.failover.Load():
+	case l.state.inline:
+		if queued = !l.state.closed.Load(); queued {
+			l.writeInline(entry)
+		}
+	default:
+		queued = l.queue(entry)
+	}
+	if !queued {
+		l.writeFailover(entry.level, entry.msg, entry.allFields())
+	}
+}
+
+// queue puts entry on the queue, or drops it when the queue is full. It
+// returns false once the logger is closed: Close marks it closed under the
+// same lock, so no entry is queued after the writer's last drain.
+func (l *Logger) queue(entry logEntry) bool {
+	l.state.sending.RLock()
+	defer l.state.sending.RUnlock()
+	if l.state.closed.Load() {
+		return false
+	}
+	if !entry.level.AtLeast(LoggerLevelError) && len(l.logChan) >= cap(l.logChan)-urgentCapacity {
+		l.drop(entry)
+		return true
+	}
 	select {
-	case l.logChan <- logEntry{level: level, msg: msg, fields: fields}:
+	case l.logChan <- entry:
 	default:
-		// Channel is full, log a warning and drop the message
-		fmt.Fprintf(os.Stderr, "Warning: Logger channel full, dropping message: %s\n", msg)
+		l.drop(entry)
 	}
+	return true
 }
 
-func (l *Logger) With(fields Fields) *Logger {
+// Flush blocks until every entry queued before the call has been written,
+// then flushes outputs and sinks that buffer internally (those with a
+// Flush() error method). The logger stays usable afterwards.
+func (l *Logger) Flush() {
+	if l.state.closed.Load() {
+		return
+	}
+	if l.state.inline {
+		l.writeInline(logEntry{flush: true})
+		return
+	}
+	l.sendWait(logEntry{flush: true})
+}
+
+// sendWait queues entry and waits until the writer has handled it. It
+// returns false, without waiting, once the logger is closed.
+func (l *Logger) sendWait(entry logEntry) bool {
+	entry.done = make(chan struct{})
+	select {
+	case l.logChan <- entry:
+	case <-l.state.quit:
+		return false
+	}
+	select {
+	case <-entry.done:
+	case <-l.state.owner.done:
+	}
+	return true
+}
+
+type flusher interface {
+	Flush() error
+}
+
+func (l *Logger) flushOutputs() {
+	l.mu.RLock()
+	outputs := l.outputs
+	l.mu.RUnlock()
+	for _, out := range outputs {
+		var f flusher
+		if out.sink != nil {
+			f, _ = out.sink.(flusher)
+		} else {
+			f, _ = out.writer.(flusher)
+		}
+		if f == nil {
+			continue
+		}
+		if err := f.Flush(); err != nil {
+			l.warnf("Logger could not flush output: %v", err)
+		}
+	}
+}
+
+// Replay writes an existing entry, keeping its original time, and blocks
+// until the outputs have received it. Unlike the level methods it never
+// drops the entry when the queue is full.
+func (l *Logger) Replay(e *Entry) {
+	entry := logEntry{level: e.Level, msg: e.Message, fields: e.Fields, time: e.Time, stack: e.Stack, logger: l}
+	if l.state.inline {
+		l.writeInline(entry)
+		return
+	}
+	if l.state.closed.Load() || !l.sendWait(entry) {
+		l.writeFailover(entry.level, entry.msg, entry.allFields())
+	}
+}
+
+// derive returns a child logger sharing l's queue and configuration, with
+// default fields produced by edit from a copy of l's.
+func (l *Logger) derive(edit func(Fields)) *Logger {
 	l.mu.RLock()
 	newLogger := &Logger{
-		level:      l.level,
+		level:      l.level.child(),
+		live:       &liveFields{parent: l.live},
 		outputs:    make([]output, len(l.outputs)),
 		timeFormat: l.timeFormat,
+		location:   l.location,
+		formatter:  l.formatter,
+		bytesEnc:   l.bytesEnc,
+		limits:     l.limits,
+		filters:    l.filters,
 		fields:     make(Fields),
+		samplers:   l.samplers,
+		msgSampler: l.msgSampler,
+		limiters:   l.limiters,
+		exitCodes:  l.exitCodes,
+		exitFunc:   l.exitFunc,
+		onFatal:    l.onFatal,
+		redact:     l.redact,
+		ring:       l.ring,
+		fatal:      l.fatal,
+		timeout:    l.timeout,
+		color:      l.color,
+		theme:      l.theme,
+		tracer:     l.tracer,
+		clock:      l.clock,
+		ids:        l.ids,
+		entryIDs:   l.entryIDs,
+		diag:       l.diag,
+		onDrop:     l.onDrop,
+		onError:    l.onError,
+		state:      l.state,
 		logChan:    l.logChan,
 	}
 	copy(newLogger.outputs, l.outputs)
@@ -218,11 +883,107 @@ func (l *Logger) With(fields Fields) *Logger {
 	}
 	l.mu.RUnlock()
 
-	for k, v := range fields {
-		newLogger.fields[k] = v
+	edit(newLogger.fields)
+	return newLogger
+}
+
+func (l *Logger) With(fields Fields) *Logger {
+	return l.derive(func(f Fields) {
+		for k, v := range fields {
+			f[k] = v
+		}
+	})
+}
+
+// Without returns a child logger that no longer carries the given
+// inherited fields.
+func (l *Logger) Without(keys ...string) *Logger {
+	return l.derive(func(f Fields) {
+		for _, k := range keys {
+			delete(f, k)
+		}
+	})
+}
+
+// ReplaceFields returns a child logger carrying fields instead of the
+// inherited ones, such as a background job that should not keep the
+// request's user_id.
+func (l *Logger) ReplaceFields(fields Fields) *Logger {
+	return l.derive(func(f Fields) {
+		for k := range f {
+			delete(f, k)
+		}
+		for k, v := range fields {
+			f[k] = v
+		}
+	})
+}
+
+// SetField sets a field on every entry of a live logger, such as
+// leader=true after an election. It applies to l and to every logger
+// derived from l, whenever they were derived, but not to the logger l
+// came from. It overrides default fields of the same key, including those
+// a derived logger was given with With; fields passed with an entry still
+// win. Entries still queued may be written with the new value.
+func (l *Logger) SetField(key string, value interface{}) {
+	l.live.set(key, value)
+}
+
+// DeleteField removes the field key from the entries of l and of every
+// logger derived from l, whether it was set with SetField or With, until
+// SetField sets it again.
+func (l *Logger) DeleteField(key string) {
+	l.live.set(key, deletedField{})
+}
+
+// liveFields holds the fields set with SetField and DeleteField on a
+// logger. They apply over those of the logger it was derived from.
+type liveFields struct {
+	mu     sync.RWMutex
+	fields Fields
+	parent *liveFields
+}
+
+// deletedField marks a field removed with DeleteField.
+type deletedField struct{}
+
+func (f *liveFields) set(key string, value interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fields == nil {
+		f.fields = make(Fields)
 	}
+	f.fields[key] = value
+}
 
-	return newLogger
+// addTo applies f and its parents, the furthest first, to fields.
+func (f *liveFields) addTo(fields Fields) {
+	if f == nil {
+		return
+	}
+	f.parent.addTo(fields)
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	for k, v := range f.fields {
+		if _, ok := v.(deletedField); ok {
+			delete(fields, k)
+			continue
+		}
+		fields[k] = v
+	}
+}
+
+// Fields returns a copy of the logger's default fields, including those
+// inherited through With and those set with SetField and DeleteField.
+func (l *Logger) Fields() Fields {
+	l.mu.RLock()
+	fields := make(Fields, len(l.fields))
+	for k, v := range l.fields {
+		fields[k] = v
+	}
+	l.mu.RUnlock()
+	l.live.addTo(fields)
+	return fields
 }
 
 func (l *Logger) Trace(msg string, fields Fields) {
@@ -248,12 +1009,41 @@ func (l *Logger) Error(msg string, fields Fields) error {
 
 func (l *Logger) Fatal(msg string, fields Fields) {
 	l.log(LoggerLevelFatal, msg, fields)
-	os.Exit(1)
+	l.terminate(fields)
+}
+
+// terminate ends the process after a Fatal entry, or hands over to the
+// deferred fatal shutdown when one is configured.
+func (l *Logger) terminate(fields Fields) {
+	code := l.exitCode(fields)
+	if l.fatal != nil {
+		l.fatal.trigger(l, code)
+		return
+	}
+	l.drain()
+	l.exit(code)
+}
+
+// panic panics with msg once the Panic entry has been written.
+func (l *Logger) panic(msg string) {
+	l.drain()
+	panic(msg)
+}
+
+// drain writes every queued entry, so the one explaining why the program
+// is about to die is not lost with the queue. It does nothing on the writer
+// goroutine itself, as when a hook calls Fatal, which would otherwise wait
+// on itself forever.
+func (l *Logger) drain() {
+	if !l.state.inline && goroutineID() == l.state.goroutine.Load() {
+		return
+	}
+	l.Flush()
 }
 
 func (l *Logger) Panic(msg string, fields Fields) {
 	l.log(LoggerLevelPanic, msg, fields)
-	panic(msg)
+	l.panic(msg)
 }
 
 var defaultLogger *Logger
@@ -266,7 +1056,7 @@ func Setup(options ...LoggerOption) {
 		// Set sensible defaults
 		options = []LoggerOption{
 			WithLevel(LoggerLevelInfo),
-			WithTimeFormat("2006-01-02 15:04:05"),
+			WithTimeFormat(DefaultTimeFormat),
 			WithOutput(os.Stdout, false, true), // Set stdout as default output with color enabled
 			WithFields(Fields{
 				"app": os.Getenv("APP_NAME"),
@@ -276,7 +1066,7 @@ func Setup(options ...LoggerOption) {
 
 		// Add file output if LOG_FILE is set
 		if logFile := os.Getenv("LOG_FILE"); logFile != "" {
-			if f, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); err == nil {
+			if f, err := openAppendFile(logFile); err == nil {
 				options = append(options, WithOutput(f, true, false)) // Append file output with color disabled
 			}
 		}
@@ -318,18 +1108,36 @@ func Panic(msg string, fields Fields) {
 	defaultLogger.Panic(msg, fields)
 }
 
+func Flush() {
+	defaultLogger.Flush()
+}
+
 func Close() {
 	defaultLogger.Close()
 }
 
+func CloseContext(ctx context.Context) error {
+	return defaultLogger.CloseContext(ctx)
+}
+
+func CloseWithTimeout(d time.Duration) error {
+	return defaultLogger.CloseWithTimeout(d)
+}
+
+// SetLevel changes the default logger's level in place, keeping its
+// outputs and fields.
 func SetLevel(level LoggerLevel) {
-	defaultLogger = NewLogger(WithLevel(level))
+	defaultLogger.SetLevel(level)
+}
+
+func SetField(key string, value interface{}) {
+	defaultLogger.SetField(key, value)
+}
+
+func DeleteField(key string) {
+	defaultLogger.DeleteField(key)
 }
 
 func GetLevel() LoggerLevel {
-	var level LoggerLevel
-	defaultLogger.mu.RLock()
-	level = defaultLogger.level
-	defaultLogger.mu.RUnlock()
-	return level
+	return defaultLogger.Level()
 }
@@ -0,0 +1,221 @@
+package bayaan_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ahmedsat/bayaan"
+)
+
+func TestDerivedLevelFollowsParent(t *testing.T) {
+	root := bayaan.NewLogger(bayaan.WithOutput(io.Discard, false, false))
+	defer root.Close()
+	child := root.Named("child")
+	grandchild := child.With(bayaan.Fields{"k": "v"})
+
+	child.SetLevel(bayaan.LoggerLevelDebug)
+	if got := grandchild.Level(); got != bayaan.LoggerLevelDebug {
+		t.Errorf("grandchild level after child.SetLevel = %v, want DEBUG", got)
+	}
+	if got := root.Level(); got != bayaan.LoggerLevelInfo {
+		t.Errorf("root level after child.SetLevel = %v, want INFO", got)
+	}
+
+	root.SetLevel(bayaan.LoggerLevelError)
+	if got := grandchild.Level(); got != bayaan.LoggerLevelDebug {
+		t.Errorf("grandchild level after root.SetLevel = %v, want the child's DEBUG", got)
+	}
+
+	grandchild.SetLevel(bayaan.LoggerLevelWarn)
+	child.SetLevel(bayaan.LoggerLevelTrace)
+	if got := grandchild.Level(); got != bayaan.LoggerLevelWarn {
+		t.Errorf("grandchild level after its own SetLevel = %v, want WARN", got)
+	}
+}
+
+func TestDerivedLevelFollowsLaterChanges(t *testing.T) {
+	root := bayaan.NewLogger(bayaan.WithOutput(io.Discard, false, false))
+	defer root.Close()
+	grandchild := root.With(nil).With(nil)
+
+	root.SetLevel(bayaan.LoggerLevelWarn)
+	if got := grandchild.Level(); got != bayaan.LoggerLevelWarn {
+		t.Errorf("grandchild level = %v, want WARN", got)
+	}
+}
+
+func TestSetFieldReachesDerivedLoggers(t *testing.T) {
+	sink := &fieldSink{}
+	root := bayaan.NewLogger(bayaan.WithSink(sink, false), bayaan.WithFields(bayaan.Fields{"leader": false}))
+	defer root.Close()
+	child := root.Named("child")
+	grandchild := child.With(bayaan.Fields{"request": 1})
+
+	root.SetField("leader", true)
+	child.SetField("shard", 7)
+	grandchild.Info("grandchild", nil)
+	root.Info("root", nil)
+	root.Flush()
+
+	if got := sink.fields("grandchild"); got["leader"] != true || got["shard"] != 7 {
+		t.Errorf("grandchild fields = %v, want leader=true and shard=7", got)
+	}
+	if got := sink.fields("root"); got["leader"] != true || got["shard"] != nil {
+		t.Errorf("root fields = %v, want leader=true and no shard", got)
+	}
+
+	child.DeleteField("request")
+	child.DeleteField("leader")
+	grandchild.Info("after delete", nil)
+	root.Info("root after delete", nil)
+	root.Flush()
+
+	if got := sink.fields("after delete"); got["request"] != nil || got["leader"] != nil {
+		t.Errorf("grandchild fields after DeleteField = %v, want no request or leader", got)
+	}
+	if got := sink.fields("root after delete"); got["leader"] != true {
+		t.Errorf("root fields after child.DeleteField = %v, want leader=true", got)
+	}
+}
+
+// fieldSink records the fields of each entry by message.
+type fieldSink struct {
+	mu      sync.Mutex
+	entries map[string]bayaan.Fields
+}
+
+func (s *fieldSink) WriteEntry(e *bayaan.Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.entries == nil {
+		s.entries = make(map[string]bayaan.Fields)
+	}
+	s.entries[e.Message] = e.Fields
+	return nil
+}
+
+func (s *fieldSink) fields(msg string) bayaan.Fields {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.entries[msg]
+}
+
+// entrySink counts the entries it receives and records whether it was
+// closed.
+type entrySink struct {
+	mu      sync.Mutex
+	entries []string
+	closed  bool
+}
+
+func (s *entrySink) WriteEntry(e *bayaan.Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, e.Message)
+	return nil
+}
+
+func (s *entrySink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+// captureStderr redirects os.Stderr while fn runs and returns what was
+// written to it.
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = stderr }()
+	var buf bytes.Buffer
+	copied := make(chan struct{})
+	go func() {
+		io.Copy(&buf, r)
+		close(copied)
+	}()
+	fn()
+	w.Close()
+	<-copied
+	r.Close()
+	return buf.String()
+}
+
+func TestCloseRacingWithLogCalls(t *testing.T) {
+	// The window between the final drain and Close returning is narrow, so
+	// the race is run a number of times.
+	for run := 0; run < 50; run++ {
+		closeRacingWithLogCalls(t)
+	}
+}
+
+func closeRacingWithLogCalls(t *testing.T) {
+	const goroutines, perGoroutine = 8, 100
+	sink := &entrySink{}
+	var dropped atomic.Int64
+	l := bayaan.NewLogger(bayaan.WithSink(sink, false),
+		bayaan.WithOnDrop(func(bayaan.Entry) { dropped.Add(1) }))
+
+	stderr := captureStderr(t, func() {
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		for g := 0; g < goroutines; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				<-start
+				for i := 0; i < perGoroutine; i++ {
+					l.Info(fmt.Sprintf("racing entry %d/%d", g, i), nil)
+				}
+			}(g)
+		}
+		close(start)
+		l.Close()
+		wg.Wait()
+	})
+
+	sink.mu.Lock()
+	written := len(sink.entries)
+	sink.mu.Unlock()
+	late := strings.Count(stderr, "racing entry")
+	if total := written + late + int(dropped.Load()); total != goroutines*perGoroutine {
+		t.Fatalf("written %d + stderr %d + dropped %d = %d, want %d: entries were lost",
+			written, late, dropped.Load(), total, goroutines*perGoroutine)
+	}
+}
+
+func TestCloseOnDerivedLoggerClosesOwner(t *testing.T) {
+	first, second := &entrySink{}, &entrySink{}
+	root := bayaan.NewLogger(bayaan.WithSink(first, false))
+	child := root.With(bayaan.Fields{"k": "v"}).Named("child")
+	// The child was derived before the outputs changed, so closing it must
+	// close the outputs the root holds now.
+	root.Reconfigure(bayaan.WithSink(second, true))
+
+	child.Info("before close", nil)
+	child.Close()
+
+	for name, sink := range map[string]*entrySink{"first": first, "second": second} {
+		sink.mu.Lock()
+		if !sink.closed {
+			t.Errorf("closing a derived logger left the %s output open", name)
+		}
+		if len(sink.entries) != 1 || sink.entries[0] != "before close" {
+			t.Errorf("%s output got %q, want the entry logged before Close", name, sink.entries)
+		}
+		sink.mu.Unlock()
+	}
+
+	root.Close() // already closed; must not block or panic
+}
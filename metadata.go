@@ -0,0 +1,57 @@
+package bayaan
+
+import (
+	"os"
+	"runtime"
+	"runtime/debug"
+)
+
+// WithProcessFields adds fields describing the process, gathered once when
+// the option is applied:
+//
+//	hostname    os.Hostname
+//	pid         the process ID
+//	go_version  the Go release the binary was built with
+//	build       the main module's path and version, and the VCS revision,
+//	            commit time and modified flag when the binary was built
+//	            from a checkout
+//
+// Build details the binary does not carry are left out.
+func WithProcessFields() LoggerOption {
+	fields := Fields{
+		"pid":        os.Getpid(),
+		"go_version": runtime.Version(),
+	}
+	if host, err := os.Hostname(); err == nil {
+		fields["hostname"] = host
+	}
+	if build := buildFields(); len(build) > 0 {
+		fields["build"] = build
+	}
+	return WithFields(fields)
+}
+
+func buildFields() Fields {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+	build := Fields{}
+	if info.Main.Path != "" {
+		build["path"] = info.Main.Path
+	}
+	if v := info.Main.Version; v != "" && v != "(devel)" {
+		build["version"] = v
+	}
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			build["revision"] = s.Value
+		case "vcs.time":
+			build["time"] = s.Value
+		case "vcs.modified":
+			build["modified"] = s.Value == "true"
+		}
+	}
+	return build
+}
@@ -0,0 +1,114 @@
+package bayaan
+
+import (
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// Metrics is a snapshot of a logger's counters. Counters are shared by a
+// logger and everything derived from it.
+type Metrics struct {
+	Entries        map[string]uint64 `json:"entries"`         // entries written, by level
+	Dropped        uint64            `json:"dropped"`         // entries dropped because the queue was full
+	OutputErrors   []uint64          `json:"output_errors"`   // failed writes, by output in registration order
+	OutputFailures []int64           `json:"output_failures"` // writes failed in a row, by output
+	OutputNames    []string          `json:"output_names"`    // outputs as WithErrorHandler names them
+	OutputDropped  []uint64          `json:"output_dropped"`  // entries missed while behind, by output; see WithWriters
+}
+
+// countLevel records that an entry at level was written.
+func (s *writerState) countLevel(level LoggerLevel) {
+	if level >= 0 && level < LoggerLevelsCount {
+		s.entries[level].Add(1)
+		return
+	}
+	n, ok := s.custom.Load(level)
+	if !ok {
+		n, _ = s.custom.LoadOrStore(level, new(atomic.Uint64))
+	}
+	n.(*atomic.Uint64).Add(1)
+}
+
+// entryCount returns the number of entries written at level.
+func (s *writerState) entryCount(level LoggerLevel) uint64 {
+	if level >= 0 && level < LoggerLevelsCount {
+		return s.entries[level].Load()
+	}
+	if n, ok := s.custom.Load(level); ok {
+		return n.(*atomic.Uint64).Load()
+	}
+	return 0
+}
+
+// Metrics returns the current values of l's counters.
+func (l *Logger) Metrics() Metrics {
+	m := Metrics{
+		Entries: make(map[string]uint64, LoggerLevelsCount),
+		Dropped: l.state.dropped.Load(),
+	}
+	for _, level := range Levels() {
+		m.Entries[level.String()] = l.state.entryCount(level)
+	}
+	l.mu.RLock()
+	for _, out := range l.outputs {
+		m.OutputErrors = append(m.OutputErrors, out.health.errors.Load())
+		m.OutputFailures = append(m.OutputFailures, out.health.failures.Load())
+		m.OutputNames = append(m.OutputNames, outputName(out))
+		m.OutputDropped = append(m.OutputDropped, out.health.dropped.Load())
+	}
+	l.mu.RUnlock()
+	return m
+}
+
+// Expvar returns an expvar.Var rendering l's Metrics as JSON, for use with
+// expvar.Publish:
+//
+//	expvar.Publish("bayaan", logger.Expvar())
+func (l *Logger) Expvar() expvar.Var {
+	return expvar.Func(func() interface{} { return l.Metrics() })
+}
+
+// WritePrometheus writes l's counters in the Prometheus text exposition
+// format.
+func (l *Logger) WritePrometheus(w io.Writer) error {
+	m := l.Metrics()
+	b := &strings.Builder{}
+	b.WriteString("# HELP bayaan_entries_total Log entries written, by level.\n")
+	b.WriteString("# TYPE bayaan_entries_total counter\n")
+	for _, level := range Levels() {
+		fmt.Fprintf(b, "bayaan_entries_total{level=%q} %d\n", level.String(), m.Entries[level.String()])
+	}
+	b.WriteString("# HELP bayaan_dropped_entries_total Log entries dropped because the queue was full.\n")
+	b.WriteString("# TYPE bayaan_dropped_entries_total counter\n")
+	fmt.Fprintf(b, "bayaan_dropped_entries_total %d\n", m.Dropped)
+	b.WriteString("# HELP bayaan_output_errors_total Failed writes, by output index.\n")
+	b.WriteString("# TYPE bayaan_output_errors_total counter\n")
+	for i, n := range m.OutputErrors {
+		fmt.Fprintf(b, "bayaan_output_errors_total{output=\"%d\"} %d\n", i, n)
+	}
+	b.WriteString("# HELP bayaan_output_consecutive_failures Writes that failed in a row, by output index.\n")
+	b.WriteString("# TYPE bayaan_output_consecutive_failures gauge\n")
+	for i, n := range m.OutputFailures {
+		fmt.Fprintf(b, "bayaan_output_consecutive_failures{output=\"%d\",name=%q} %d\n", i, m.OutputNames[i], n)
+	}
+	b.WriteString("# HELP bayaan_output_dropped_total Entries an output missed because its writer goroutine was full, by output index.\n")
+	b.WriteString("# TYPE bayaan_output_dropped_total counter\n")
+	for i, n := range m.OutputDropped {
+		fmt.Fprintf(b, "bayaan_output_dropped_total{output=\"%d\",name=%q} %d\n", i, m.OutputNames[i], n)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// MetricsHandler serves l's counters in the Prometheus text format, for
+// scraping without depending on the Prometheus client library.
+func (l *Logger) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		l.WritePrometheus(w)
+	})
+}
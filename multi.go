@@ -0,0 +1,92 @@
+package bayaan
+
+import "errors"
+
+// LevelLogger is the set of logging methods shared by Logger and
+// MultiLogger, for code that should accept either.
+type LevelLogger interface {
+	Trace(msg string, fields Fields)
+	Debug(msg string, fields Fields)
+	Info(msg string, fields Fields)
+	Warn(msg string, fields Fields)
+	Error(msg string, fields Fields) error
+	Fatal(msg string, fields Fields)
+	Panic(msg string, fields Fields)
+}
+
+// MultiLogger sends every call to several independent loggers.
+type MultiLogger struct {
+	loggers []*Logger
+}
+
+// Multi returns a MultiLogger fanning out to loggers. Fatal exits the way
+// the first logger is configured to, after all of them wrote the entry.
+func Multi(loggers ...*Logger) *MultiLogger {
+	return &MultiLogger{loggers: loggers}
+}
+
+func (m *MultiLogger) log(level LoggerLevel, msg string, fields Fields) {
+	for _, l := range m.loggers {
+		l.log(level, msg, fields)
+	}
+}
+
+func (m *MultiLogger) With(fields Fields) *MultiLogger {
+	loggers := make([]*Logger, len(m.loggers))
+	for i, l := range m.loggers {
+		loggers[i] = l.With(fields)
+	}
+	return &MultiLogger{loggers: loggers}
+}
+
+func (m *MultiLogger) Trace(msg string, fields Fields) {
+	m.log(LoggerLevelTrace, msg, fields)
+}
+
+func (m *MultiLogger) Debug(msg string, fields Fields) {
+	m.log(LoggerLevelDebug, msg, fields)
+}
+
+func (m *MultiLogger) Info(msg string, fields Fields) {
+	m.log(LoggerLevelInfo, msg, fields)
+}
+
+func (m *MultiLogger) Warn(msg string, fields Fields) {
+	m.log(LoggerLevelWarn, msg, fields)
+}
+
+func (m *MultiLogger) Error(msg string, fields Fields) error {
+	m.log(LoggerLevelError, msg, fields)
+	return errors.New(msg)
+}
+
+func (m *MultiLogger) Fatal(msg string, fields Fields) {
+	m.log(LoggerLevelFatal, msg, fields)
+	if len(m.loggers) == 0 {
+		return
+	}
+	for _, l := range m.loggers[1:] {
+		l.drain()
+	}
+	m.loggers[0].terminate(fields)
+}
+
+func (m *MultiLogger) Panic(msg string, fields Fields) {
+	m.log(LoggerLevelPanic, msg, fields)
+	for _, l := range m.loggers {
+		l.drain()
+	}
+	panic(msg)
+}
+
+// Close closes every logger.
+func (m *MultiLogger) Close() {
+	for _, l := range m.loggers {
+		l.Close()
+	}
+}
+
+var (
+	_ LevelLogger = (*Logger)(nil)
+	_ LevelLogger = (*MultiLogger)(nil)
+)
@@ -0,0 +1,53 @@
+package bayaan_test
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ahmedsat/bayaan"
+)
+
+// slowWriter records what it is given, taking a while for each write.
+type slowWriter struct {
+	mu  sync.Mutex
+	buf strings.Builder
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(10 * time.Millisecond)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *slowWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestMultiFatalDrainsEveryLogger(t *testing.T) {
+	code := -1
+	first := bayaan.NewLogger(bayaan.WithOutput(io.Discard, false, false),
+		bayaan.WithExitFunc(func(c int) { code = c }))
+	defer first.Close()
+	w := &slowWriter{}
+	second := bayaan.NewLogger(bayaan.WithOutput(w, false, false))
+	defer second.Close()
+
+	m := bayaan.Multi(first, second)
+	for i := 0; i < 5; i++ {
+		m.Info("working", nil)
+	}
+	m.Fatal("giving up", nil)
+
+	if code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+	if out := w.String(); strings.Count(out, "working") != 5 || !strings.Contains(out, "giving up") {
+		t.Errorf("second logger wrote %q before the exit, want every entry", out)
+	}
+}
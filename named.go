@@ -0,0 +1,92 @@
+package bayaan
+
+import (
+	"strings"
+	"sync"
+)
+
+// registry holds the loggers handed out by Named and the levels set with
+// SetLevelFor.
+var registry = struct {
+	sync.Mutex
+	loggers map[string]*namedLogger
+	levels  map[string]LoggerLevel
+}{
+	loggers: make(map[string]*namedLogger),
+	levels:  make(map[string]LoggerLevel),
+}
+
+type namedLogger struct {
+	base   *Logger // the default logger it was derived from
+	logger *Logger
+}
+
+// Named returns the logger for the subsystem name, creating it on first
+// use. Names are hierarchical, with dots separating levels ("db",
+// "db.pool"). A named logger writes through the default logger with a
+// "logger" field holding its name, at the level set for the closest name
+// with SetLevelFor, or the default logger's level.
+//
+// Loggers obtained before Setup keep writing to the logger Setup replaced;
+// call Named again afterwards.
+func Named(name string) *Logger {
+	registry.Lock()
+	defer registry.Unlock()
+	if n := registry.loggers[name]; n != nil && n.base == defaultLogger {
+		return n.logger
+	}
+
+	base := defaultLogger
+	l := base.derive(func(f Fields) { f["logger"] = name })
+	if level, ok := levelFor(name); ok {
+		l.setLevel(level)
+	}
+	registry.loggers[name] = &namedLogger{base: base, logger: l}
+	return l
+}
+
+// Named returns a child logger for the subsystem name, with a "logger"
+// field holding its name. Names nest: a logger named "server" returns
+// "server.http" for Named("http"), and children made with With keep the
+// name. Unlike the package-level Named, the logger is not registered, so
+// SetLevelFor does not apply to it.
+func (l *Logger) Named(name string) *Logger {
+	return l.derive(func(f Fields) {
+		if parent, ok := f["logger"].(string); ok && parent != "" {
+			name = parent + "." + name
+		}
+		f["logger"] = name
+	})
+}
+
+// SetLevelFor sets the level of the named logger and of every logger below
+// it in the hierarchy without a more specific level of its own:
+//
+//	bayaan.SetLevelFor("db", bayaan.LoggerLevelDebug) // db, db.pool, db.tx
+func SetLevelFor(name string, level LoggerLevel) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.levels[name] = level
+	for n, named := range registry.loggers {
+		if n == name || strings.HasPrefix(n, name+".") {
+			if level, ok := levelFor(n); ok {
+				named.logger.SetLevel(level)
+			}
+		}
+	}
+}
+
+// levelFor returns the level set for name or its closest ancestor. The
+// registry lock must be held.
+func levelFor(name string) (LoggerLevel, bool) {
+	for {
+		if level, ok := registry.levels[name]; ok {
+			return level, true
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			return 0, false
+		}
+		name = name[:i]
+	}
+}
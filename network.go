@@ -0,0 +1,144 @@
+package bayaan
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	networkWriteTimeout = 5 * time.Second
+	networkMaxBackoff   = 30 * time.Second
+)
+
+// NetworkWriter writes to a TCP or UDP endpoint, such as a logstash or
+// fluent-bit forwarder. While the connection is down, writes are buffered
+// in memory up to a byte limit, dropping the oldest first, and a
+// background goroutine reconnects with exponential backoff.
+type NetworkWriter struct {
+	network   string
+	addr      string
+	maxBuffer int
+
+	mu           sync.Mutex
+	conn         net.Conn
+	buffer       [][]byte
+	buffered     int
+	reconnecting bool
+	closed       bool
+	done         chan struct{}
+}
+
+// NewNetworkWriter starts connecting to addr in the background. Writes
+// made before the connection is up are buffered.
+func NewNetworkWriter(network, addr string, maxBufferBytes int) *NetworkWriter {
+	w := &NetworkWriter{
+		network:   network,
+		addr:      addr,
+		maxBuffer: maxBufferBytes,
+		done:      make(chan struct{}),
+	}
+	w.mu.Lock()
+	w.reconnect()
+	w.mu.Unlock()
+	return w
+}
+
+func (w *NetworkWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn != nil {
+		w.conn.SetWriteDeadline(time.Now().Add(networkWriteTimeout))
+		if _, err := w.conn.Write(p); err == nil {
+			return len(p), nil
+		}
+		w.conn.Close()
+		w.conn = nil
+		w.reconnect()
+	}
+	w.bufferWrite(p)
+	return len(p), nil
+}
+
+func (w *NetworkWriter) bufferWrite(p []byte) {
+	w.buffer = append(w.buffer, append([]byte(nil), p...))
+	w.buffered += len(p)
+	for w.buffered > w.maxBuffer && len(w.buffer) > 0 {
+		w.buffered -= len(w.buffer[0])
+		w.buffer = w.buffer[1:]
+	}
+}
+
+// reconnect starts the reconnect loop unless one is running. w.mu must be
+// held.
+func (w *NetworkWriter) reconnect() {
+	if w.reconnecting || w.closed {
+		return
+	}
+	w.reconnecting = true
+
+	go func() {
+		backoff := 100 * time.Millisecond
+		for {
+			conn, err := net.DialTimeout(w.network, w.addr, networkWriteTimeout)
+			if err == nil && w.connected(conn) {
+				return
+			}
+			select {
+			case <-w.done:
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, networkMaxBackoff)
+		}
+	}()
+}
+
+// connected sends the buffered writes over conn and makes it the current
+// connection, reporting whether that succeeded.
+func (w *NetworkWriter) connected(conn net.Conn) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		conn.Close()
+		return true
+	}
+	for len(w.buffer) > 0 {
+		conn.SetWriteDeadline(time.Now().Add(networkWriteTimeout))
+		if _, err := conn.Write(w.buffer[0]); err != nil {
+			conn.Close()
+			return false
+		}
+		w.buffered -= len(w.buffer[0])
+		w.buffer = w.buffer[1:]
+	}
+	w.conn = conn
+	w.reconnecting = false
+	return true
+}
+
+// Close closes the connection. Writes still buffered are lost.
+func (w *NetworkWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	close(w.done)
+	if w.conn != nil {
+		return w.conn.Close()
+	}
+	return nil
+}
+
+// WithNetworkOutput adds an uncolored NetworkWriter output buffering up to
+// 1 MB while disconnected.
+func WithNetworkOutput(network, addr string) LoggerOption {
+	return func(l *Logger) {
+		w := NewNetworkWriter(network, addr, 1<<20)
+		l.addOutput(output{writer: w, closer: w}, true)
+	}
+}
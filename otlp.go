@@ -0,0 +1,144 @@
+package bayaan
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var otlpSeverities = map[LoggerLevel]int{
+	LoggerLevelTrace: 1,
+	LoggerLevelDebug: 5,
+	LoggerLevelInfo:  9,
+	LoggerLevelWarn:  13,
+	LoggerLevelError: 17,
+	LoggerLevelFatal: 21,
+	LoggerLevelPanic: 24,
+}
+
+// OTLPEncoder encodes batches as an OTLP/HTTP JSON ExportLogsServiceRequest.
+// Fields become LogRecord attributes, except "trace_id" and "span_id",
+// which fill the record's trace context.
+type OTLPEncoder struct {
+	// Resource holds the resource attributes, e.g. "service.name".
+	Resource map[string]string
+}
+
+type otlpKeyValue struct {
+	Key   string                 `json:"key"`
+	Value map[string]interface{} `json:"value"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano         string         `json:"timeUnixNano"`
+	ObservedTimeUnixNano string         `json:"observedTimeUnixNano"`
+	SeverityNumber       int            `json:"severityNumber"`
+	SeverityText         string         `json:"severityText"`
+	Body                 interface{}    `json:"body"`
+	Attributes           []otlpKeyValue `json:"attributes,omitempty"`
+	TraceID              string         `json:"traceId,omitempty"`
+	SpanID               string         `json:"spanId,omitempty"`
+}
+
+func otlpValue(v interface{}) map[string]interface{} {
+	switch v := v.(type) {
+	case string:
+		return map[string]interface{}{"stringValue": v}
+	case bool:
+		return map[string]interface{}{"boolValue": v}
+	case int:
+		return map[string]interface{}{"intValue": strconv.FormatInt(int64(v), 10)}
+	case int8, int16, int32, int64, uint8, uint16, uint32:
+		return map[string]interface{}{"intValue": sprint(v)}
+	case uint:
+		if uint64(v) <= math.MaxInt64 {
+			return map[string]interface{}{"intValue": strconv.FormatUint(uint64(v), 10)}
+		}
+	case uint64:
+		if v <= math.MaxInt64 {
+			return map[string]interface{}{"intValue": strconv.FormatUint(v, 10)}
+		}
+	case float32:
+		return otlpDouble(float64(v))
+	case float64:
+		return otlpDouble(v)
+	case error:
+		return map[string]interface{}{"stringValue": v.Error()}
+	}
+	return map[string]interface{}{"stringValue": sprint(v)}
+}
+
+func otlpDouble(v float64) map[string]interface{} {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return map[string]interface{}{"stringValue": sprint(v)}
+	}
+	return map[string]interface{}{"doubleValue": v}
+}
+
+func otlpAttributes(attrs map[string]interface{}) []otlpKeyValue {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	kvs := make([]otlpKeyValue, 0, len(keys))
+	for _, k := range keys {
+		kvs = append(kvs, otlpKeyValue{Key: k, Value: otlpValue(attrs[k])})
+	}
+	return kvs
+}
+
+func (enc OTLPEncoder) Encode(entries []*Entry) ([]byte, string, error) {
+	observed := strconv.FormatInt(time.Now().UnixNano(), 10)
+	records := make([]otlpLogRecord, 0, len(entries))
+	for _, e := range entries {
+		severity, ok := otlpSeverities[e.Level]
+		if !ok {
+			severity = 9
+		}
+		record := otlpLogRecord{
+			TimeUnixNano:         strconv.FormatInt(e.Time.UnixNano(), 10),
+			ObservedTimeUnixNano: observed,
+			SeverityNumber:       severity,
+			SeverityText:         e.Level.String(),
+			Body:                 map[string]string{"stringValue": e.Message},
+		}
+		attrs := make(map[string]interface{}, len(e.Fields))
+		for k, v := range e.Fields {
+			switch k {
+			case "trace_id":
+				record.TraceID = sprint(v)
+			case "span_id":
+				record.SpanID = sprint(v)
+			default:
+				attrs[k] = v
+			}
+		}
+		record.Attributes = otlpAttributes(attrs)
+		records = append(records, record)
+	}
+
+	resource := make(map[string]interface{}, len(enc.Resource))
+	for k, v := range enc.Resource {
+		resource[k] = v
+	}
+	payload := map[string]interface{}{
+		"resourceLogs": []interface{}{map[string]interface{}{
+			"resource": map[string]interface{}{"attributes": otlpAttributes(resource)},
+			"scopeLogs": []interface{}{map[string]interface{}{
+				"scope":      map[string]string{"name": "github.com/ahmedsat/bayaan"},
+				"logRecords": records,
+			}},
+		}},
+	}
+	body, err := json.Marshal(payload)
+	return body, "application/json", err
+}
+
+// NewOTLPSink creates an HTTPSink exporting to an OTLP/HTTP logs endpoint,
+// e.g. http://localhost:4318/v1/logs.
+func NewOTLPSink(endpoint string, resource map[string]string, options ...HTTPOption) *HTTPSink {
+	return NewHTTPSink(endpoint, OTLPEncoder{Resource: resource}, options...)
+}
@@ -0,0 +1,37 @@
+package bayaan
+
+import "sync"
+
+// maxPooledBuffer keeps the occasional huge entry from pinning memory in
+// the pool.
+const maxPooledBuffer = 64 << 10
+
+// bufferPool recycles the buffers entries are rendered into. Entries
+// themselves are not pooled: they travel through the queue by value, and
+// hooks and sinks may keep the *Entry and its Fields.
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, 1024)
+		return &b
+	},
+}
+
+func getBuffer() *[]byte {
+	return bufferPool.Get().(*[]byte)
+}
+
+// putBuffer returns b, which now holds buf, to the pool. Callers must not
+// put back a buffer an abandoned write may still be reading.
+func putBuffer(b *[]byte, buf []byte) {
+	if cap(buf) > maxPooledBuffer {
+		return
+	}
+	*b = buf[:0]
+	bufferPool.Put(b)
+}
+
+// appendFormatter is implemented by formatters able to render into a
+// caller-supplied buffer, letting the logger reuse it across entries.
+type appendFormatter interface {
+	AppendFormat(dst []byte, e *Entry) ([]byte, error)
+}
@@ -0,0 +1,93 @@
+package bayaan
+
+import "fmt"
+
+// splitFields separates an optional trailing Fields argument from the
+// format arguments.
+func splitFields(args []interface{}) ([]interface{}, Fields) {
+	if n := len(args); n > 0 {
+		if fields, ok := args[n-1].(Fields); ok {
+			return args[:n-1], fields
+		}
+	}
+	return args, nil
+}
+
+// logf formats and logs the message if level is enabled, skipping the
+// formatting cost otherwise.
+func (l *Logger) logf(level LoggerLevel, format string, args []interface{}) {
+	if !l.enabled(level) {
+		return
+	}
+	args, fields := splitFields(args)
+	l.log(level, fmt.Sprintf(format, args...), fields)
+}
+
+// Tracef logs a message formatted with fmt.Sprintf. A Fields value as the
+// last argument is used as the entry's fields rather than formatted; the
+// same holds for the other *f methods.
+func (l *Logger) Tracef(format string, args ...interface{}) {
+	l.logf(LoggerLevelTrace, format, args)
+}
+
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.logf(LoggerLevelDebug, format, args)
+}
+
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.logf(LoggerLevelInfo, format, args)
+}
+
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.logf(LoggerLevelWarn, format, args)
+}
+
+// Errorf logs the formatted message and returns it as an error built by
+// fmt.Errorf, so %w wraps as usual.
+func (l *Logger) Errorf(format string, args ...interface{}) error {
+	args, fields := splitFields(args)
+	err := fmt.Errorf(format, args...)
+	l.log(LoggerLevelError, err.Error(), fields)
+	return err
+}
+
+func (l *Logger) Fatalf(format string, args ...interface{}) {
+	args, fields := splitFields(args)
+	l.log(LoggerLevelFatal, fmt.Sprintf(format, args...), fields)
+	l.terminate(fields)
+}
+
+func (l *Logger) Panicf(format string, args ...interface{}) {
+	args, fields := splitFields(args)
+	msg := fmt.Sprintf(format, args...)
+	l.log(LoggerLevelPanic, msg, fields)
+	l.panic(msg)
+}
+
+func Tracef(format string, args ...interface{}) {
+	defaultLogger.Tracef(format, args...)
+}
+
+func Debugf(format string, args ...interface{}) {
+	defaultLogger.Debugf(format, args...)
+}
+
+func Infof(format string, args ...interface{}) {
+	defaultLogger.Infof(format, args...)
+}
+
+func Warnf(format string, args ...interface{}) {
+	defaultLogger.Warnf(format, args...)
+}
+
+func Errorf(format string, args ...interface{}) error {
+	return defaultLogger.Errorf(format, args...)
+}
+
+func Fatalf(format string, args ...interface{}) {
+	defaultLogger.Fatalf(format, args...)
+}
+
+func Panicf(format string, args ...interface{}) {
+	defaultLogger.Panicf(format, args...)
+}
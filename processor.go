@@ -0,0 +1,138 @@
+package bayaan
+
+import (
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+// FieldProcessor is a Hook that rewrites every field value of every entry,
+// e.g. to anonymize or shorten it before it reaches any output.
+type FieldProcessor func(key string, value interface{}) interface{}
+
+func (p FieldProcessor) Levels() []LoggerLevel {
+	return LevelsFrom(LoggerLevelTrace)
+}
+
+func (p FieldProcessor) Fire(e *Entry) error {
+	for k, v := range e.Fields {
+		e.Fields[k] = p(k, v)
+	}
+	return nil
+}
+
+// onlyKeys restricts p to the given keys, or leaves it unrestricted when
+// there are none.
+func onlyKeys(keys []string, p FieldProcessor) FieldProcessor {
+	if len(keys) == 0 {
+		return p
+	}
+	set := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		set[k] = true
+	}
+	return func(key string, value interface{}) interface{} {
+		if !set[key] {
+			return value
+		}
+		return p(key, value)
+	}
+}
+
+// AnonymizeIPs zeroes the last octet of IPv4 addresses and everything past
+// the /48 prefix of IPv6 addresses, keeping any port. It applies to the
+// given keys, or to every field holding an address when none are given.
+func AnonymizeIPs(keys ...string) FieldProcessor {
+	return onlyKeys(keys, func(key string, value interface{}) interface{} {
+		switch v := value.(type) {
+		case net.IP:
+			if addr, ok := netip.AddrFromSlice(v); ok {
+				return anonymizeAddr(addr.Unmap()).String()
+			}
+		case netip.Addr:
+			return anonymizeAddr(v).String()
+		case netip.AddrPort:
+			return netip.AddrPortFrom(anonymizeAddr(v.Addr()), v.Port()).String()
+		case string:
+			if addr, err := netip.ParseAddr(v); err == nil {
+				return anonymizeAddr(addr).String()
+			}
+			if ap, err := netip.ParseAddrPort(v); err == nil {
+				return netip.AddrPortFrom(anonymizeAddr(ap.Addr()), ap.Port()).String()
+			}
+		}
+		return value
+	})
+}
+
+func anonymizeAddr(addr netip.Addr) netip.Addr {
+	bits := 24
+	if addr.Is6() && !addr.Is4In6() {
+		bits = 48
+	} else if addr.Is4In6() {
+		addr = addr.Unmap()
+	}
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return addr
+	}
+	return prefix.Addr()
+}
+
+// DefaultSecretParams are the query parameters ScrubURLs redacts when
+// called without any.
+var DefaultSecretParams = []string{
+	"token", "access_token", "refresh_token", "id_token", "api_key", "apikey",
+	"key", "password", "passwd", "secret", "client_secret", "signature", "sig", "auth",
+}
+
+// ScrubURLs removes user credentials from URL-valued fields and replaces
+// the values of the given query parameters (DefaultSecretParams when none
+// are given) with REDACTED. Matching is case-insensitive.
+func ScrubURLs(secretParams ...string) FieldProcessor {
+	if len(secretParams) == 0 {
+		secretParams = DefaultSecretParams
+	}
+	secrets := make(map[string]bool, len(secretParams))
+	for _, p := range secretParams {
+		secrets[strings.ToLower(p)] = true
+	}
+
+	return func(key string, value interface{}) interface{} {
+		var u *url.URL
+		switch v := value.(type) {
+		case *url.URL:
+			if v == nil {
+				return value
+			}
+			copied := *v
+			u = &copied
+		case url.URL:
+			u = &v
+		case string:
+			if !strings.Contains(v, "://") {
+				return value
+			}
+			parsed, err := url.Parse(v)
+			if err != nil || parsed.Host == "" {
+				return value
+			}
+			u = parsed
+		default:
+			return value
+		}
+
+		u.User = nil
+		if u.RawQuery != "" {
+			query := u.Query()
+			for name := range query {
+				if secrets[strings.ToLower(name)] {
+					query.Set(name, "REDACTED")
+				}
+			}
+			u.RawQuery = query.Encode()
+		}
+		return u.String()
+	}
+}
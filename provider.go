@@ -0,0 +1,83 @@
+package bayaan
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// Clock supplies the time the logger stamps on entries and uses for
+// sampling, rate limiting and deduplication. Replacing it lets simulation
+// and deterministic-testing frameworks drive the logger reproducibly.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to Clock.
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time { return f() }
+
+// IDGenerator supplies the unique identifiers the logger hands out.
+type IDGenerator interface {
+	NewID() string
+}
+
+// IDGeneratorFunc adapts a function to IDGenerator.
+type IDGeneratorFunc func() string
+
+func (f IDGeneratorFunc) NewID() string { return f() }
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// randomIDs generates 128-bit random identifiers in hex.
+type randomIDs struct{}
+
+func (randomIDs) NewID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// WithClockSource replaces the wall clock used for entry timestamps,
+// sampling, rate limiting and deduplication. Timers, write deadlines and
+// the watchdog keep using real time.
+func WithClockSource(c Clock) LoggerOption {
+	return func(l *Logger) {
+		l.mu.Lock()
+		l.clock = c
+		l.mu.Unlock()
+	}
+}
+
+// WithClock is WithClockSource for a plain function, typically a fixed or
+// stepping time in golden-file tests:
+//
+//	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+//	logger := bayaan.NewLogger(bayaan.WithClock(func() time.Time { return at }))
+func WithClock(now func() time.Time) LoggerOption {
+	return WithClockSource(ClockFunc(now))
+}
+
+// WithIDGenerator replaces the source of identifiers returned by NewID.
+func WithIDGenerator(g IDGenerator) LoggerOption {
+	return func(l *Logger) {
+		l.mu.Lock()
+		l.ids = g
+		l.mu.Unlock()
+	}
+}
+
+// NewID returns a fresh identifier from the logger's IDGenerator, random
+// 128-bit hex by default.
+func (l *Logger) NewID() string {
+	return l.ids.NewID()
+}
+
+// now reads the logger's clock. The clock and ID generator are only set
+// while the logger is being built, so they are read without locking.
+func (l *Logger) now() time.Time {
+	return l.clock.Now()
+}
@@ -0,0 +1,132 @@
+package bayaan
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type rateWindow struct {
+	start      time.Time
+	count      int
+	suppressed int
+	level      LoggerLevel
+	value      interface{}
+	timer      *time.Timer
+}
+
+type rateLimiter struct {
+	key    string
+	limit  int
+	window time.Duration
+	emit   func(LoggerLevel, string, Fields)
+
+	mu      sync.Mutex
+	stopped bool
+	windows map[string]*rateWindow
+}
+
+func (r *rateLimiter) allow(now time.Time, level LoggerLevel, msg string, fields Fields) bool {
+	var value interface{} = msg
+	if r.key != "" {
+		v, ok := fields[r.key]
+		if !ok {
+			return true
+		}
+		value = v
+	}
+	k := sprint(value)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w := r.windows[k]
+	if w == nil || now.Sub(w.start) >= r.window {
+		if w != nil && w.timer != nil {
+			// The summary for the old window is still pending; send it now
+			// so it comes before the entries of the new one.
+			w.timer.Stop()
+			r.summarize(w)
+		}
+		w = &rateWindow{start: now, value: value}
+		r.windows[k] = w
+		r.sweep(now)
+	}
+	w.count++
+	if w.count <= r.limit {
+		return true
+	}
+
+	w.suppressed++
+	w.level = max(w.level, level)
+	if w.timer == nil {
+		w.timer = time.AfterFunc(w.start.Add(r.window).Sub(now), func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if r.stopped || r.windows[k] != w {
+				return
+			}
+			delete(r.windows, k)
+			r.summarize(w)
+		})
+	}
+	return false
+}
+
+func (r *rateLimiter) summarize(w *rateWindow) {
+	key := r.key
+	if key == "" {
+		key = "msg"
+	}
+	r.emit(w.level, fmt.Sprintf("suppressed %d similar entries", w.suppressed), Fields{
+		"suppressed": w.suppressed,
+		key:          w.value,
+	})
+}
+
+// sweep forgets expired windows with nothing left to summarize, so keys
+// with many distinct values don't accumulate forever.
+func (r *rateLimiter) sweep(now time.Time) {
+	if len(r.windows) < 1024 {
+		return
+	}
+	for k, w := range r.windows {
+		if w.timer == nil && now.Sub(w.start) >= r.window {
+			delete(r.windows, k)
+		}
+	}
+}
+
+// stop emits the pending summaries and disables further ones.
+func (r *rateLimiter) stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stopped {
+		return
+	}
+	r.stopped = true
+	for _, w := range r.windows {
+		if w.timer != nil && w.timer.Stop() {
+			r.summarize(w)
+		}
+	}
+}
+
+// WithRateLimit lets through at most limit entries per window for each
+// distinct value of the field named key, or of the message when key is
+// empty. Entries without the field are not limited. When a window ends
+// with entries suppressed, a single "suppressed N similar entries" entry
+// is written in their place.
+func WithRateLimit(key string, limit int, window time.Duration) LoggerOption {
+	return func(l *Logger) {
+		l.mu.Lock()
+		l.limiters = append(l.limiters, &rateLimiter{
+			key:     key,
+			limit:   limit,
+			window:  window,
+			emit:    l.enqueue,
+			windows: make(map[string]*rateWindow),
+		})
+		l.mu.Unlock()
+	}
+}
@@ -0,0 +1,63 @@
+package bayaan
+
+import (
+	"fmt"
+	"strings"
+)
+
+// redactedText replaces every masked value.
+const redactedText = "***"
+
+// Secret wraps a sensitive value, such as a token or an email address, so
+// that it renders as "***" in every output, sink and hook:
+//
+//	logger.Info("login", bayaan.Fields{"user": bayaan.Secret(email)})
+type Secret string
+
+func (Secret) String() string   { return redactedText }
+func (Secret) GoString() string { return redactedText }
+
+// Format keeps fmt verbs like %s, %q and %x from reaching the value.
+func (Secret) Format(f fmt.State, verb rune) { f.Write([]byte(redactedText)) }
+
+func (Secret) MarshalJSON() ([]byte, error) { return []byte(`"` + redactedText + `"`), nil }
+
+func (Secret) MarshalText() ([]byte, error) { return []byte(redactedText), nil }
+
+// WithRedaction masks the values of the given field keys as "***",
+// matching keys case-insensitively at any group depth. Masking happens
+// before hooks run, so nothing downstream sees the value.
+func WithRedaction(keys []string) LoggerOption {
+	return func(l *Logger) {
+		l.mu.Lock()
+		if l.redact == nil {
+			l.redact = make(map[string]bool, len(keys))
+		}
+		for _, k := range keys {
+			l.redact[strings.ToLower(k)] = true
+		}
+		l.mu.Unlock()
+	}
+}
+
+// redact masks v if its key is redacted or it is a Secret. Groups are
+// copied rather than modified, since the caller may still own them.
+func redact(key string, v interface{}, keys map[string]bool, depth int) interface{} {
+	if len(keys) > 0 && keys[strings.ToLower(key)] {
+		return redactedText
+	}
+	switch v := v.(type) {
+	case Secret:
+		return redactedText
+	case Fields:
+		if depth >= maxGroupDepth || v == nil {
+			return v
+		}
+		out := make(Fields, len(v))
+		for k, gv := range v {
+			out[k] = redact(k, gv, keys, depth+1)
+		}
+		return out
+	}
+	return v
+}
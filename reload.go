@@ -0,0 +1,147 @@
+package bayaan
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// reopener is implemented by outputs backed by a file that can be opened
+// again by path, such as RotatingFile.
+type reopener interface {
+	Reopen() error
+}
+
+// appendFile is a file opened for appending that can be reopened by path.
+type appendFile struct {
+	path string
+	mu   sync.Mutex
+	f    *os.File
+}
+
+func openAppendFile(path string) (*appendFile, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &appendFile{path: path, f: f}, nil
+}
+
+func (a *appendFile) Write(p []byte) (int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.f.Write(p)
+}
+
+func (a *appendFile) Reopen() error {
+	f, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	old := a.f
+	a.f = f
+	a.mu.Unlock()
+	return old.Close()
+}
+
+func (a *appendFile) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.f.Close()
+}
+
+// control runs fn on the writer, between entries, and waits for it, so fn
+// can change outputs without racing a write.
+func (l *Logger) control(fn func()) {
+	entry := logEntry{control: fn}
+	if l.state.inline {
+		l.writeInline(entry)
+		return
+	}
+	l.sendWait(entry)
+}
+
+// Reopen reopens the logger's file outputs by path, after entries queued
+// before the call have been written. Call it once an external tool has
+// moved the files away, as logrotate does, so writing continues in new
+// files instead of the renamed or deleted ones.
+func (l *Logger) Reopen() error {
+	var errs []error
+	l.control(func() {
+		owner := l.state.owner
+		owner.mu.RLock()
+		outputs := owner.outputs
+		owner.mu.RUnlock()
+		for _, out := range outputs {
+			if r, ok := out.writer.(reopener); ok && out.sink == nil {
+				if err := r.Reopen(); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// Reconfigure applies options to a live logger, after entries queued before
+// the call have been written. Outputs the options replace are closed. It
+// applies to the logger NewLogger built, which every derived logger writes
+// through. Options that start background work, such as WithWatchdog, start
+// it again each time and are best left out.
+func (l *Logger) Reconfigure(options ...LoggerOption) {
+	l.control(func() {
+		// Options replacing outputs close them; see closeOutputs.
+		for _, option := range options {
+			option(l.state.owner)
+		}
+	})
+}
+
+// WithReloadOnSIGHUP reconfigures the logger with the options returned by
+// reload and then reopens its file outputs whenever the process receives
+// SIGHUP, the conventional signal for both after log rotation. A nil reload
+// only reopens. If reload fails the configuration is kept, the error is
+// reported through the diagnostics writer and files are still reopened.
+//
+//	bayaan.NewLogger(append(options, bayaan.WithReloadOnSIGHUP(func() ([]bayaan.LoggerOption, error) {
+//		return bayaan.ConfigFromFile("/etc/app/logging.yaml")
+//	}))...)
+func WithReloadOnSIGHUP(reload func() ([]LoggerOption, error)) LoggerOption {
+	return func(l *Logger) {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGHUP)
+		stop := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for {
+				select {
+				case <-stop:
+					return
+				case <-signals:
+				}
+				if reload != nil {
+					if options, err := reload(); err != nil {
+						l.warnf("Logger could not reload configuration: %v", err)
+					} else {
+						l.Reconfigure(options...)
+					}
+				}
+				if err := l.Reopen(); err != nil {
+					l.warnf("Logger could not reopen outputs: %v", err)
+				}
+			}
+		}()
+
+		l.mu.Lock()
+		l.stops = append(l.stops, func() {
+			signal.Stop(signals)
+			close(stop)
+			<-done
+		})
+		l.mu.Unlock()
+	}
+}
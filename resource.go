@@ -0,0 +1,192 @@
+package bayaan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ResourceDetector reports fields describing where the process runs, such
+// as its pod or cloud region. It returns nil when the process is not
+// running there. Keys follow the OpenTelemetry resource conventions.
+type ResourceDetector func(ctx context.Context) Fields
+
+// Endpoints queried by the cloud detectors; variables so tests can point
+// them elsewhere.
+var (
+	awsMetadataURL   = "http://169.254.169.254"
+	gcpMetadataURL   = "http://metadata.google.internal"
+	azureMetadataURL = "http://169.254.169.254"
+	k8sNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+// WithResourceFields runs the detectors, all of them concurrently and
+// within timeout, and adds what they find as default fields. With no
+// detectors it runs KubernetesDetector, AWSDetector, GCPDetector and
+// AzureDetector. Detection happens once, when the option is applied, and
+// outside a cloud it costs up to timeout, spent waiting on metadata
+// endpoints that do not answer.
+func WithResourceFields(timeout time.Duration, detectors ...ResourceDetector) LoggerOption {
+	if len(detectors) == 0 {
+		detectors = []ResourceDetector{KubernetesDetector, AWSDetector, GCPDetector, AzureDetector}
+	}
+	return func(l *Logger) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		fields := Fields{}
+		for _, detect := range detectors {
+			wg.Add(1)
+			go func(detect ResourceDetector) {
+				defer wg.Done()
+				found := detect(ctx)
+				mu.Lock()
+				for k, v := range found {
+					fields[k] = v
+				}
+				mu.Unlock()
+			}(detect)
+		}
+		wg.Wait()
+		WithFields(fields)(l)
+	}
+}
+
+// KubernetesDetector reads the pod's identity from the environment. The
+// namespace comes from the service account mount; the rest must be exposed
+// through the downward API as POD_NAME, POD_NAMESPACE, NODE_NAME and
+// CONTAINER_NAME (the pod name falls back to HOSTNAME):
+//
+//	env:
+//	  - name: POD_NAME
+//	    valueFrom: {fieldRef: {fieldPath: metadata.name}}
+//	  - name: NODE_NAME
+//	    valueFrom: {fieldRef: {fieldPath: spec.nodeName}}
+func KubernetesDetector(ctx context.Context) Fields {
+	if os.Getenv("KUBERNETES_SERVICE_HOST") == "" {
+		return nil
+	}
+	fields := Fields{}
+	pod := os.Getenv("POD_NAME")
+	if pod == "" {
+		pod = os.Getenv("HOSTNAME")
+	}
+	setNonEmpty(fields, "k8s.pod.name", pod)
+	namespace := os.Getenv("POD_NAMESPACE")
+	if namespace == "" {
+		if b, err := os.ReadFile(k8sNamespaceFile); err == nil {
+			namespace = strings.TrimSpace(string(b))
+		}
+	}
+	setNonEmpty(fields, "k8s.namespace.name", namespace)
+	setNonEmpty(fields, "k8s.node.name", os.Getenv("NODE_NAME"))
+	setNonEmpty(fields, "k8s.container.name", os.Getenv("CONTAINER_NAME"))
+	return fields
+}
+
+// AWSDetector reads the region, availability zone and instance ID from the
+// EC2 instance metadata service (IMDSv2), or the region from the
+// environment on Lambda and ECS.
+func AWSDetector(ctx context.Context) Fields {
+	if region := os.Getenv("AWS_REGION"); region != "" && os.Getenv("AWS_EXECUTION_ENV") != "" {
+		return Fields{"cloud.provider": "aws", "cloud.region": region}
+	}
+
+	token, err := metadataGet(ctx, http.MethodPut, awsMetadataURL+"/latest/api/token",
+		map[string]string{"X-aws-ec2-metadata-token-ttl-seconds": "60"})
+	if err != nil {
+		return nil
+	}
+	header := map[string]string{"X-aws-ec2-metadata-token": token}
+	get := func(path string) string {
+		v, _ := metadataGet(ctx, http.MethodGet, awsMetadataURL+"/latest/meta-data/"+path, header)
+		return v
+	}
+	fields := Fields{"cloud.provider": "aws"}
+	setNonEmpty(fields, "cloud.region", get("placement/region"))
+	setNonEmpty(fields, "cloud.availability_zone", get("placement/availability-zone"))
+	setNonEmpty(fields, "host.id", get("instance-id"))
+	return fields
+}
+
+// GCPDetector reads the project, zone and region from the Google Cloud
+// metadata server, adding the service name on Cloud Run.
+func GCPDetector(ctx context.Context) Fields {
+	header := map[string]string{"Metadata-Flavor": "Google"}
+	project, err := metadataGet(ctx, http.MethodGet, gcpMetadataURL+"/computeMetadata/v1/project/project-id", header)
+	if err != nil {
+		return nil
+	}
+	fields := Fields{"cloud.provider": "gcp", "cloud.account.id": project}
+	if zone, err := metadataGet(ctx, http.MethodGet, gcpMetadataURL+"/computeMetadata/v1/instance/zone", header); err == nil {
+		// projects/123/zones/europe-west1-b
+		zone = zone[strings.LastIndexByte(zone, '/')+1:]
+		setNonEmpty(fields, "cloud.availability_zone", zone)
+		if i := strings.LastIndexByte(zone, '-'); i > 0 {
+			fields["cloud.region"] = zone[:i]
+		}
+	}
+	setNonEmpty(fields, "service.name", os.Getenv("K_SERVICE"))
+	return fields
+}
+
+// AzureDetector reads the location and VM ID from the Azure instance
+// metadata service.
+func AzureDetector(ctx context.Context) Fields {
+	body, err := metadataGet(ctx, http.MethodGet, azureMetadataURL+"/metadata/instance/compute?api-version=2021-02-01",
+		map[string]string{"Metadata": "true"})
+	if err != nil {
+		return nil
+	}
+	var compute struct {
+		Location string `json:"location"`
+		VMID     string `json:"vmId"`
+		Zone     string `json:"zone"`
+	}
+	if json.Unmarshal([]byte(body), &compute) != nil || compute.Location == "" {
+		return nil
+	}
+	fields := Fields{"cloud.provider": "azure", "cloud.region": compute.Location}
+	setNonEmpty(fields, "cloud.availability_zone", compute.Zone)
+	setNonEmpty(fields, "host.id", compute.VMID)
+	return fields
+}
+
+func setNonEmpty(fields Fields, key, value string) {
+	if value != "" {
+		fields[key] = value
+	}
+}
+
+// metadataGet fetches a small metadata document, failing on any status
+// other than 200.
+func metadataGet(ctx context.Context, method, url string, header map[string]string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return "", err
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("bayaan: %s: %s", url, resp.Status)
+	}
+	return strings.TrimSpace(string(body)), nil
+}
@@ -0,0 +1,67 @@
+package bayaan
+
+// ring holds the most recent TRACE and DEBUG entries. It is only touched
+// by the writer goroutine.
+type ring struct {
+	entries []logEntry
+	next    int
+	full    bool
+}
+
+func (r *ring) push(entry logEntry) {
+	entry.done = nil
+	r.entries[r.next] = entry
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// drain returns the buffered entries oldest first and empties the ring.
+func (r *ring) drain() []logEntry {
+	var out []logEntry
+	if r.full {
+		out = append(out, r.entries[r.next:]...)
+	}
+	out = append(out, r.entries[:r.next]...)
+	clear(r.entries)
+	r.next, r.full = 0, false
+	return out
+}
+
+// WithRingBuffer keeps the last size TRACE and DEBUG entries in memory
+// instead of writing them, regardless of the logger's level. When an ERROR
+// or more severe entry is logged they are written just before it, giving
+// the context around a failure without paying for debug output the rest of
+// the time. Buffered entries keep the time they were logged at.
+func WithRingBuffer(size int) LoggerOption {
+	return func(l *Logger) {
+		if size <= 0 {
+			return
+		}
+		l.mu.Lock()
+		l.ring = &ring{entries: make([]logEntry, size)}
+		l.mu.Unlock()
+	}
+}
+
+// buffer stores entry in the ring if it is debug chatter, reporting
+// whether it did so. ERROR and above first flush the ring to the outputs.
+func (l *Logger) buffer(entry logEntry) bool {
+	if l.ring == nil {
+		return false
+	}
+	if LoggerLevelDebug.AtLeast(entry.level) {
+		if entry.time.IsZero() {
+			entry.time = l.now()
+		}
+		l.ring.push(entry)
+		return true
+	}
+	if entry.level.AtLeast(LoggerLevelError) {
+		for _, buffered := range l.ring.drain() {
+			l.write(buffered)
+		}
+	}
+	return false
+}
@@ -0,0 +1,240 @@
+package bayaan
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotatingFile is an io.WriteCloser that rolls the file at its path over
+// to a timestamped backup (path.2006-01-02T15-04-05.000) once it grows past
+// a size or has been written to for longer than an age limit.
+type RotatingFile struct {
+	path       string
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+	compress   bool
+
+	mu       sync.Mutex
+	wg       sync.WaitGroup
+	file     *os.File
+	size     int64
+	openedAt time.Time
+}
+
+type RotateOption func(*RotatingFile)
+
+// WithCompression gzips each backup in the background after rotation.
+func WithCompression() RotateOption {
+	return func(r *RotatingFile) {
+		r.compress = true
+	}
+}
+
+// NewRotatingFile opens path for appending. A maxSizeMB or maxAge of 0
+// disables that trigger, and a maxBackups of 0 keeps every backup.
+func NewRotatingFile(path string, maxSizeMB int, maxAge time.Duration, maxBackups int, options ...RotateOption) (*RotatingFile, error) {
+	r := &RotatingFile{
+		path:       path,
+		maxSize:    int64(maxSizeMB) * 1024 * 1024,
+		maxAge:     maxAge,
+		maxBackups: maxBackups,
+	}
+	for _, option := range options {
+		option(r)
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.file = f
+	r.size = info.Size()
+	r.openedAt = time.Now()
+	return nil
+}
+
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+	if r.size > 0 && r.due(int64(len(p))) {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *RotatingFile) due(incoming int64) bool {
+	if r.maxSize > 0 && r.size+incoming > r.maxSize {
+		return true
+	}
+	return r.maxAge > 0 && time.Since(r.openedAt) >= r.maxAge
+}
+
+// Rotate rolls the current file over immediately.
+func (r *RotatingFile) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rotate()
+}
+
+// Reopen closes the file and opens path again, picking up a file that was
+// moved away by an external tool such as logrotate.
+func (r *RotatingFile) Reopen() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file != nil {
+		r.file.Close()
+		r.file = nil
+	}
+	return r.open()
+}
+
+func (r *RotatingFile) rotate() error {
+	if r.file != nil {
+		if err := r.file.Close(); err != nil {
+			return err
+		}
+		r.file = nil
+	}
+
+	backup := r.path + "." + time.Now().Format(backupTimeFormat)
+	if err := os.Rename(r.path, backup); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := r.open(); err != nil {
+		return err
+	}
+
+	if r.compress {
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			if err := compressFile(backup); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: Logger could not compress %s: %v\n", backup, err)
+			}
+			r.mu.Lock()
+			r.removeOldBackups()
+			r.mu.Unlock()
+		}()
+		return nil
+	}
+	r.removeOldBackups()
+	return nil
+}
+
+func compressFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmp := name + ".gz.tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	_, err = io.Copy(gz, src)
+	if err == nil {
+		err = gz.Close()
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, name+".gz")
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Remove(name)
+}
+
+// backups returns the existing backups of the file, oldest first.
+func (r *RotatingFile) backups() []string {
+	matches, _ := filepath.Glob(r.path + ".*")
+	backups := matches[:0]
+	for _, m := range matches {
+		suffix := strings.TrimSuffix(strings.TrimPrefix(m, r.path+"."), ".gz")
+		if _, err := time.Parse(backupTimeFormat, suffix); err == nil {
+			backups = append(backups, m)
+		}
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return strings.TrimSuffix(backups[i], ".gz") < strings.TrimSuffix(backups[j], ".gz")
+	})
+	return backups
+}
+
+func (r *RotatingFile) removeOldBackups() {
+	if r.maxBackups <= 0 {
+		return
+	}
+	backups := r.backups()
+	for len(backups) > r.maxBackups {
+		os.Remove(backups[0])
+		backups = backups[1:]
+	}
+}
+
+// Close closes the file after any background compression has finished.
+func (r *RotatingFile) Close() error {
+	r.wg.Wait()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// WithRotatingFile adds a RotatingFile output without colors. See
+// NewRotatingFile for the meaning of the limits.
+func WithRotatingFile(path string, maxSizeMB int, maxAge time.Duration, maxBackups int, options ...RotateOption) LoggerOption {
+	return func(l *Logger) {
+		r, err := NewRotatingFile(path, maxSizeMB, maxAge, maxBackups, options...)
+		if err != nil {
+			l.warnf("Logger could not open %s: %v", path, err)
+			return
+		}
+		l.addOutput(output{writer: r, closer: r}, true)
+	}
+}
@@ -0,0 +1,68 @@
+package bayaan
+
+import "os"
+
+// withLevels applies option, restricting the outputs it adds to entries
+// whose level accept returns true for.
+func withLevels(accept func(LoggerLevel) bool, option LoggerOption) LoggerOption {
+	return withAccept(func(e *Entry) bool { return accept(e.Level) }, option)
+}
+
+// withAccept applies option, restricting the outputs it adds to the
+// entries accept returns true for, in addition to any restriction they
+// already have.
+func withAccept(accept func(*Entry) bool, option LoggerOption) LoggerOption {
+	return func(l *Logger) {
+		l.editAdded(option, func(out *output) {
+			if prev := out.accept; prev != nil {
+				out.accept = func(e *Entry) bool { return prev(e) && accept(e) }
+			} else {
+				out.accept = accept
+			}
+		})
+	}
+}
+
+// WithOutputLevel restricts the outputs and sinks registered by option to
+// entries at min or above, so they receive only part of what the logger
+// writes:
+//
+//	bayaan.NewLogger(
+//		bayaan.WithOutput(os.Stdout, false, true),
+//		bayaan.WithOutputLevel(bayaan.LoggerLevelError,
+//			bayaan.WithRotatingFile("/var/log/app/errors.log", 100, 0, 7)),
+//	)
+//
+// The logger's own level still applies first.
+func WithOutputLevel(min LoggerLevel, option LoggerOption) LoggerOption {
+	return withLevels(func(level LoggerLevel) bool { return level.AtLeast(min) }, option)
+}
+
+// WithOutputLevelRange restricts the outputs and sinks registered by
+// option to entries from min to max, inclusive. To keep TRACE and DEBUG in
+// a file of their own, set the logger's level to TRACE and route the rest
+// elsewhere:
+//
+//	bayaan.NewLogger(
+//		bayaan.WithLevel(bayaan.LoggerLevelTrace),
+//		bayaan.WithOutputLevel(bayaan.LoggerLevelInfo, bayaan.WithOutput(os.Stdout, false, true)),
+//		bayaan.WithOutputLevelRange(bayaan.LoggerLevelTrace, bayaan.LoggerLevelDebug,
+//			bayaan.WithRotatingFile("/var/log/app/debug.log", 100, 0, 3)),
+//	)
+func WithOutputLevelRange(min, max LoggerLevel, option LoggerOption) LoggerOption {
+	return withLevels(func(level LoggerLevel) bool { return level.AtLeast(min) && max.AtLeast(level) }, option)
+}
+
+// WithSplitStreams replaces the outputs with the twelve-factor pair: WARN
+// and above to stderr, everything below to stdout, both in the same format
+// so the two streams read alike when shown together. CI systems and
+// container platforms then classify problems correctly.
+func WithSplitStreams(useColor bool) LoggerOption {
+	return func(l *Logger) {
+		withoutOutputs()(l)
+		withLevels(func(level LoggerLevel) bool { return !level.AtLeast(LoggerLevelWarn) },
+			WithOutput(os.Stdout, true, useColor))(l)
+		withLevels(func(level LoggerLevel) bool { return level.AtLeast(LoggerLevelWarn) },
+			WithOutput(os.Stderr, true, useColor))(l)
+	}
+}
@@ -0,0 +1,75 @@
+package bayaan
+
+import (
+	"os"
+	"runtime"
+	"time"
+)
+
+// openFDs counts the process's open file descriptors, or returns -1 where
+// that isn't available.
+func openFDs() int {
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return -1
+	}
+	return len(entries)
+}
+
+// RuntimeStats returns the current Go runtime statistics as fields.
+func RuntimeStats() Fields {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	fields := Fields{
+		"goroutines":      runtime.NumGoroutine(),
+		"heap_alloc":      m.HeapAlloc,
+		"heap_sys":        m.HeapSys,
+		"heap_objects":    m.HeapObjects,
+		"num_gc":          m.NumGC,
+		"gc_pause_total":  time.Duration(m.PauseTotalNs),
+		"gc_pause_last":   time.Duration(m.PauseNs[(m.NumGC+255)%256]),
+		"gc_cpu_fraction": m.GCCPUFraction,
+	}
+	if fds := openFDs(); fds >= 0 {
+		fields["open_fds"] = fds
+	}
+	return fields
+}
+
+// LogRuntimeStats logs RuntimeStats at INFO.
+func (l *Logger) LogRuntimeStats() {
+	if !l.enabled(LoggerLevelInfo) {
+		return
+	}
+	l.log(LoggerLevelInfo, "runtime stats", RuntimeStats())
+}
+
+// WithRuntimeStats logs RuntimeStats every interval until the logger is
+// closed.
+func WithRuntimeStats(interval time.Duration) LoggerOption {
+	return func(l *Logger) {
+		stop := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					l.LogRuntimeStats()
+				case <-stop:
+					return
+				}
+			}
+		}()
+
+		l.mu.Lock()
+		l.stops = append(l.stops, func() {
+			close(stop)
+			<-done
+		})
+		l.mu.Unlock()
+	}
+}
@@ -0,0 +1,142 @@
+package bayaan
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type sampler struct {
+	tick       time.Duration
+	first      uint64
+	thereafter uint64
+
+	mu    sync.Mutex
+	reset time.Time
+	count uint64
+}
+
+func (s *sampler) sample(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !now.Before(s.reset) {
+		s.reset = now.Add(s.tick)
+		s.count = 0
+	}
+	s.count++
+	if s.count <= s.first {
+		return true
+	}
+	if s.thereafter == 0 {
+		return false
+	}
+	return (s.count-s.first)%s.thereafter == 0
+}
+
+// WithSampling limits entries of the given level to the first `first` per
+// second, then keeps one of every `thereafter` until the second is over.
+// A thereafter of 0 drops everything past the first entries; negative
+// counts are taken as 0. Sampled-out entries never reach the queue.
+func WithSampling(level LoggerLevel, first, thereafter int) LoggerOption {
+	return func(l *Logger) {
+		first, thereafter := l.sampleCount("first", first), l.sampleCount("thereafter", thereafter)
+		l.mu.Lock()
+		if l.samplers == nil {
+			l.samplers = make(map[LoggerLevel]*sampler)
+		}
+		l.samplers[level] = &sampler{
+			tick:       time.Second,
+			first:      first,
+			thereafter: thereafter,
+		}
+		l.mu.Unlock()
+	}
+}
+
+// sampleCount returns n for a sampling option, warning about and clamping
+// negative values to 0.
+func (l *Logger) sampleCount(name string, n int) uint64 {
+	if n < 0 {
+		l.warnf("Logger sampling %s must not be negative, got %d; using 0", name, n)
+		return 0
+	}
+	return uint64(n)
+}
+
+// messageSamplerSlots is the number of counters messages are hashed into.
+// Messages sharing a slot share a count, which bounds memory however many
+// distinct messages are logged.
+const messageSamplerSlots = 4096
+
+// messageSampler counts entries per level and message, as zap's sampler
+// does.
+type messageSampler struct {
+	tick       time.Duration
+	first      uint64
+	thereafter uint64
+	counts     [messageSamplerSlots]sampleCounter
+}
+
+type sampleCounter struct {
+	resetAt atomic.Int64
+	count   atomic.Uint64
+}
+
+func (s *messageSampler) sample(now time.Time, level LoggerLevel, msg string) bool {
+	// FNV-1a over the level and message, without hash/fnv's allocations.
+	h := uint32(2166136261) ^ uint32(level)
+	h *= 16777619
+	for i := 0; i < len(msg); i++ {
+		h ^= uint32(msg[i])
+		h *= 16777619
+	}
+	n := s.counts[h%messageSamplerSlots].inc(now, s.tick)
+	if n <= s.first {
+		return true
+	}
+	if s.thereafter == 0 {
+		return false
+	}
+	return (n-s.first)%s.thereafter == 0
+}
+
+// inc counts one entry, restarting the count once the tick it began in is
+// over.
+func (c *sampleCounter) inc(now time.Time, tick time.Duration) uint64 {
+	t := now.UnixNano()
+	resetAt := c.resetAt.Load()
+	if resetAt > t {
+		return c.count.Add(1)
+	}
+	c.count.Store(1)
+	if !c.resetAt.CompareAndSwap(resetAt, t+int64(tick)) {
+		// Another entry started the new tick first.
+		return c.count.Add(1)
+	}
+	return 1
+}
+
+// WithMessageSampling keeps the first `first` entries with the same level
+// and message in every tick, then one of every `thereafter`, with counts
+// starting over each tick. A thereafter of 0 drops everything past the
+// first entries; negative counts are taken as 0, and a tick that is not
+// positive as one second. Unlike WithSampling it applies to every level,
+// and a noisy message does not crowd out others at its level. Sampled-out
+// entries never reach the queue.
+func WithMessageSampling(tick time.Duration, first, thereafter int) LoggerOption {
+	return func(l *Logger) {
+		if tick <= 0 {
+			l.warnf("Logger message sampling tick must be positive, got %v; using 1s", tick)
+			tick = time.Second
+		}
+		first, thereafter := l.sampleCount("first", first), l.sampleCount("thereafter", thereafter)
+		l.mu.Lock()
+		l.msgSampler = &messageSampler{
+			tick:       tick,
+			first:      first,
+			thereafter: thereafter,
+		}
+		l.mu.Unlock()
+	}
+}
@@ -0,0 +1,55 @@
+package bayaan_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ahmedsat/bayaan"
+)
+
+func TestSamplingClampsNegativeCounts(t *testing.T) {
+	var diag bytes.Buffer
+	sink := &entrySink{}
+	l := bayaan.NewLogger(bayaan.WithSink(sink, false), bayaan.WithDiagnostics(&diag),
+		bayaan.WithSampling(bayaan.LoggerLevelInfo, -1, -5))
+	defer l.Close()
+
+	for i := 0; i < 5; i++ {
+		l.Info("sampled", nil)
+	}
+	l.Flush()
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if len(sink.entries) != 0 {
+		t.Errorf("%d entries written with negative sampling counts, want 0", len(sink.entries))
+	}
+	if !strings.Contains(diag.String(), "must not be negative") {
+		t.Errorf("diagnostics = %q, want a warning about the negative counts", diag.String())
+	}
+}
+
+func TestMessageSamplingValidatesInputs(t *testing.T) {
+	var diag bytes.Buffer
+	sink := &entrySink{}
+	l := bayaan.NewLogger(bayaan.WithSink(sink, false), bayaan.WithDiagnostics(&diag),
+		bayaan.WithMessageSampling(0, -1, 2))
+	defer l.Close()
+
+	for i := 0; i < 4; i++ {
+		l.Info("noisy", nil)
+	}
+	l.Flush()
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if len(sink.entries) != 2 {
+		t.Errorf("%d entries written, want 2: none first, then one of every 2", len(sink.entries))
+	}
+	for _, want := range []string{"tick must be positive", "must not be negative"} {
+		if !strings.Contains(diag.String(), want) {
+			t.Errorf("diagnostics = %q, want a warning containing %q", diag.String(), want)
+		}
+	}
+}
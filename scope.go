@@ -0,0 +1,49 @@
+package bayaan
+
+import "time"
+
+// Scope is an operation started with Begin, logged with its duration when
+// it ends.
+type Scope struct {
+	logger *Logger
+	name   string
+	fields Fields
+	start  time.Time
+}
+
+// Begin starts timing the operation name and returns the scope to end
+// when it is over:
+//
+//	scope := logger.Begin("migrate db", bayaan.Fields{"version": 42})
+//	err := migrate()
+//	return scope.End(err)
+//
+// Nothing is logged until End.
+func (l *Logger) Begin(name string, fields Fields) *Scope {
+	return &Scope{logger: l, name: name, fields: fields, start: l.now()}
+}
+
+// End logs the operation with its fields and a "duration" field: at INFO
+// as "<name> done" when err is nil, at ERROR as "<name> failed" with the
+// error otherwise. It returns err, so it can end a function. Call it once.
+func (s *Scope) End(err error) error {
+	l := s.logger
+	typed := []Field{Duration("duration", l.now().Sub(s.start))}
+	if err != nil {
+		typed = append(typed, Err(err))
+		l.emit(logEntry{level: LoggerLevelError, msg: s.name + " failed", fields: s.fields, typed: typed, logger: l})
+		return err
+	}
+	l.emit(logEntry{level: LoggerLevelInfo, msg: s.name + " done", fields: s.fields, typed: typed, logger: l})
+	return nil
+}
+
+// Timed runs fn as the operation name, logged as by Begin and End, and
+// returns its error.
+func Timed(logger *Logger, name string, fn func() error) error {
+	return logger.Begin(name, nil).End(fn())
+}
+
+func Begin(name string, fields Fields) *Scope {
+	return defaultLogger.Begin(name, fields)
+}
@@ -0,0 +1,304 @@
+package bayaan
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SentryHook forwards ERROR and higher entries to Sentry as events, with
+// the entry's fields as extra data and its call stack as the stack trace.
+// Events are sent in the background, in batches of whatever has queued
+// up, at most 20; as Sentry takes one event per envelope, the events of a
+// batch are sent as concurrent requests over the client's pooled
+// connections. Flush or Close waits for them.
+type SentryHook struct {
+	endpoint    string
+	auth        string
+	dsn         string
+	environment string
+	release     string
+	client      *http.Client
+
+	mu     sync.Mutex // guards queue against Close
+	closed bool
+	queue  chan []byte
+	wg     sync.WaitGroup
+}
+
+// sentryBatch is the most events sent at once.
+const sentryBatch = 20
+
+type SentryOption func(*SentryHook)
+
+func WithSentryEnvironment(environment string) SentryOption {
+	return func(h *SentryHook) {
+		h.environment = environment
+	}
+}
+
+func WithSentryRelease(release string) SentryOption {
+	return func(h *SentryHook) {
+		h.release = release
+	}
+}
+
+// NewSentryHook creates a hook for the project identified by dsn, e.g.
+// https://publickey@o0.ingest.sentry.io/123.
+func NewSentryHook(dsn string, options ...SentryOption) (*SentryHook, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, err
+	}
+	project := strings.TrimPrefix(u.Path, "/")
+	if u.User == nil || project == "" {
+		return nil, fmt.Errorf("bayaan: invalid sentry DSN %q", dsn)
+	}
+	path := ""
+	if i := strings.LastIndex(project, "/"); i >= 0 {
+		path, project = "/"+project[:i], project[i+1:]
+	}
+
+	h := &SentryHook{
+		endpoint: fmt.Sprintf("%s://%s%s/api/%s/envelope/", u.Scheme, u.Host, path, project),
+		auth:     "Sentry sentry_version=7, sentry_client=bayaan/1.0, sentry_key=" + u.User.Username(),
+		dsn:      dsn,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		queue:    make(chan []byte, 100),
+	}
+
+	for _, option := range options {
+		option(h)
+	}
+
+	go h.run()
+	return h, nil
+}
+
+func (h *SentryHook) Levels() []LoggerLevel {
+	return LevelsFrom(LoggerLevelError)
+}
+
+type sentryFrame struct {
+	Function string `json:"function"`
+	Module   string `json:"module,omitempty"`
+	Filename string `json:"filename"`
+	AbsPath  string `json:"abs_path"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+type sentryException struct {
+	Type       string `json:"type"`
+	Value      string `json:"value"`
+	Stacktrace *struct {
+		Frames []sentryFrame `json:"frames"`
+	} `json:"stacktrace,omitempty"`
+}
+
+type sentryEvent struct {
+	EventID     string                     `json:"event_id"`
+	Timestamp   string                     `json:"timestamp"`
+	Level       string                     `json:"level"`
+	Logger      string                     `json:"logger"`
+	Platform    string                     `json:"platform"`
+	Environment string                     `json:"environment,omitempty"`
+	Release     string                     `json:"release,omitempty"`
+	ServerName  string                     `json:"server_name,omitempty"`
+	Message     map[string]string          `json:"message"`
+	Extra       map[string]json.RawMessage `json:"extra,omitempty"`
+	Exception   []sentryException          `json:"exception,omitempty"`
+}
+
+func (h *SentryHook) Fire(e *Entry) error {
+	id := make([]byte, 16)
+	rand.Read(id)
+	hostname, _ := os.Hostname()
+
+	level := "error"
+	if e.Level.AtLeast(LoggerLevelFatal) {
+		level = "fatal"
+	}
+	event := sentryEvent{
+		EventID:     hex.EncodeToString(id),
+		Timestamp:   e.Time.UTC().Format(time.RFC3339Nano),
+		Level:       level,
+		Logger:      "bayaan",
+		Platform:    "go",
+		Environment: h.environment,
+		Release:     h.release,
+		ServerName:  hostname,
+		Message:     map[string]string{"formatted": e.Message},
+		Extra:       make(map[string]json.RawMessage, len(e.Fields)),
+	}
+
+	exception := sentryException{Type: e.Level.String(), Value: e.Message}
+	enc := &JSONFormatter{}
+	for k, v := range e.Fields {
+		if exception.Type == e.Level.String() {
+			switch v := v.(type) {
+			case error:
+				exception.Type = fmt.Sprintf("%T", v)
+				exception.Value = errorText(v)
+			case Fields: // from Err
+				if t, ok := v["type"].(string); ok && k == "error" {
+					exception.Type = t
+					exception.Value, _ = v["message"].(string)
+				}
+			}
+		}
+		buf := &bytes.Buffer{}
+		enc.writeValue(buf, v)
+		event.Extra[k] = buf.Bytes()
+	}
+
+	if frames := e.Frames(); len(frames) > 0 {
+		exception.Stacktrace = &struct {
+			Frames []sentryFrame `json:"frames"`
+		}{}
+		// Sentry expects the outermost frame first.
+		for i := len(frames) - 1; i >= 0; i-- {
+			f := frames[i]
+			module, function := splitFunction(f.Function)
+			exception.Stacktrace.Frames = append(exception.Stacktrace.Frames, sentryFrame{
+				Function: function,
+				Module:   module,
+				Filename: f.File,
+				AbsPath:  f.File,
+				Lineno:   f.Line,
+				InApp:    !strings.HasPrefix(module, "runtime") && !strings.Contains(f.File, "/pkg/mod/"),
+			})
+		}
+	}
+	event.Exception = []sentryException{exception}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	header, _ := json.Marshal(map[string]string{
+		"event_id": event.EventID,
+		"sent_at":  time.Now().UTC().Format(time.RFC3339Nano),
+		"dsn":      h.dsn,
+	})
+	envelope := &bytes.Buffer{}
+	envelope.Write(header)
+	envelope.WriteString("\n")
+	fmt.Fprintf(envelope, `{"type":"event","length":%d}`, len(payload))
+	envelope.WriteString("\n")
+	envelope.Write(payload)
+	envelope.WriteString("\n")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return fmt.Errorf("bayaan: sentry hook closed, dropping event")
+	}
+	h.wg.Add(1)
+	select {
+	case h.queue <- envelope.Bytes():
+		return nil
+	default:
+		h.wg.Done()
+		return fmt.Errorf("bayaan: sentry queue full, dropping event")
+	}
+}
+
+func splitFunction(name string) (string, string) {
+	slash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[slash+1:], ".")
+	if dot < 0 {
+		return "", name
+	}
+	return name[:slash+1+dot], name[slash+2+dot:]
+}
+
+func (h *SentryHook) run() {
+	for envelope := range h.queue {
+		batch := [][]byte{envelope}
+	collect:
+		for len(batch) < sentryBatch {
+			select {
+			case envelope, ok := <-h.queue:
+				if !ok {
+					break collect
+				}
+				batch = append(batch, envelope)
+			default:
+				break collect
+			}
+		}
+		h.sendBatch(batch)
+	}
+}
+
+// sendBatch sends the envelopes of batch concurrently and waits for them.
+func (h *SentryHook) sendBatch(batch [][]byte) {
+	var sent sync.WaitGroup
+	for _, envelope := range batch {
+		sent.Add(1)
+		go func() {
+			defer sent.Done()
+			defer h.wg.Done()
+			if err := h.send(envelope); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: Logger could not send event to sentry: %v\n", err)
+			}
+		}()
+	}
+	sent.Wait()
+}
+
+func (h *SentryHook) send(envelope []byte) error {
+	req, err := http.NewRequest(http.MethodPost, h.endpoint, bytes.NewReader(envelope))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", h.auth)
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("sentry responded %s", resp.Status)
+	}
+	return nil
+}
+
+// Flush waits until every queued event has been sent or timeout passes,
+// and reports whether the queue was drained.
+func (h *SentryHook) Flush(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		h.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// Close sends the queued events, waiting up to five seconds. Events fired
+// afterwards are dropped with an error.
+func (h *SentryHook) Close() error {
+	h.Flush(5 * time.Second)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.closed {
+		h.closed = true
+		close(h.queue)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package bayaan
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// WithFlushOnSignal makes the process, on SIGINT or SIGTERM (or the given
+// signals), close the logger, writing what is queued within timeout, and
+// then die of the signal as it would have without the logger. Orchestrators
+// send SIGTERM before killing a container, so this keeps the last entries
+// an asynchronous logger would otherwise lose.
+//
+// It is meant for programs that do not handle these signals themselves.
+// Programs that do should call CloseWithTimeout at the end of their own
+// shutdown instead, as after the signal entries go to stderr.
+func WithFlushOnSignal(timeout time.Duration, signals ...os.Signal) LoggerOption {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	return func(l *Logger) {
+		received := make(chan os.Signal, 1)
+		signal.Notify(received, signals...)
+		stop := make(chan struct{})
+		go func() {
+			var sig os.Signal
+			select {
+			case <-stop:
+				return
+			case sig = <-received:
+			}
+			signal.Stop(received)
+			if err := l.CloseWithTimeout(timeout); err != nil {
+				l.warnf("Logger could not flush on %v: %v", sig, err)
+			}
+			reraise(sig)
+		}()
+
+		l.mu.Lock()
+		l.stops = append(l.stops, func() {
+			signal.Stop(received)
+			close(stop)
+		})
+		l.mu.Unlock()
+	}
+}
+
+// reraise delivers sig to the process again with its default handling
+// restored, falling back to exiting with the shell's 128+n status where
+// signals cannot be sent.
+func reraise(sig os.Signal) {
+	signal.Reset(sig)
+	if p, err := os.FindProcess(os.Getpid()); err == nil && p.Signal(sig) == nil {
+		return
+	}
+	code := 1
+	if s, ok := sig.(syscall.Signal); ok {
+		code = 128 + int(s)
+	}
+	os.Exit(code)
+}
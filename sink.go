@@ -0,0 +1,20 @@
+package bayaan
+
+import "io"
+
+// Sink is an output that receives the structured entry rather than its
+// formatted text, for destinations that need timestamps, levels or fields
+// separately. A Sink that implements io.Closer is closed with the logger.
+type Sink interface {
+	WriteEntry(entry *Entry) error
+}
+
+func WithSink(sink Sink, additive bool) LoggerOption {
+	return func(l *Logger) {
+		out := output{sink: sink}
+		if c, ok := sink.(io.Closer); ok {
+			out.closer = c
+		}
+		l.addOutput(out, additive)
+	}
+}
@@ -0,0 +1,95 @@
+package bayaan
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Spooled batches are files named after the time they were spooled, so
+// sorting the names sorts them oldest first. Each holds the content type,
+// a newline, and the request body.
+const spoolExt = ".batch"
+
+// spool saves a batch for replay, then trims the spool to its size limit.
+func (s *HTTPSink) spool(body []byte, contentType string) error {
+	if err := os.MkdirAll(s.spoolDir, 0700); err != nil {
+		return err
+	}
+	name := filepath.Join(s.spoolDir, fmt.Sprintf("%020d", time.Now().UnixNano())+spoolExt)
+	data := make([]byte, 0, len(contentType)+1+len(body))
+	data = append(append(append(data, contentType...), '\n'), body...)
+	// Written under another name first, so replay never sees half a batch.
+	if err := os.WriteFile(name+".tmp", data, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(name+".tmp", name); err != nil {
+		return err
+	}
+	s.trimSpool()
+	return nil
+}
+
+func (s *HTTPSink) spooled() []string {
+	names, _ := filepath.Glob(filepath.Join(s.spoolDir, "*"+spoolExt))
+	sort.Strings(names)
+	return names
+}
+
+// trimSpool deletes the oldest batches while the spool exceeds its limit.
+func (s *HTTPSink) trimSpool() {
+	if s.spoolMax <= 0 {
+		return
+	}
+	names := s.spooled()
+	sizes := make([]int64, len(names))
+	var total int64
+	for i, name := range names {
+		if info, err := os.Stat(name); err == nil {
+			sizes[i] = info.Size()
+			total += sizes[i]
+		}
+	}
+	dropped := 0
+	for i := 0; total > s.spoolMax && i < len(names)-1; i++ {
+		if os.Remove(names[i]) == nil {
+			total -= sizes[i]
+			dropped++
+		}
+	}
+	if dropped > 0 {
+		s.report(fmt.Errorf("spool %s over %d bytes, deleted the %d oldest batches", s.spoolDir, s.spoolMax, dropped))
+	}
+}
+
+// replay sends the spooled batches oldest first, one attempt each,
+// stopping at the first failure.
+func (s *HTTPSink) replay() error {
+	if s.spoolDir == "" {
+		return nil
+	}
+	for _, name := range s.spooled() {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		contentType, body, ok := bytes.Cut(data, []byte("\n"))
+		if !ok {
+			os.Remove(name)
+			continue
+		}
+		retry, err := s.do(body, string(contentType))
+		if err != nil && retry {
+			return nil // still down; try again next interval
+		}
+		// Sent, or rejected for good.
+		os.Remove(name)
+		if err != nil {
+			return fmt.Errorf("spooled batch %s: %w", filepath.Base(name), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package bayaan
+
+import "errors"
+
+// badKey holds values that could not be paired with a key, as log/slog
+// does.
+const badKey = "!BADKEY"
+
+// fieldsFromPairs builds Fields from alternating keys and values. A Field
+// may stand in for a pair. A non-string key, or a key left without a
+// value, is stored under "!BADKEY" instead of being lost.
+func fieldsFromPairs(kv []interface{}) Fields {
+	if len(kv) == 0 {
+		return nil
+	}
+	fields := make(Fields, (len(kv)+1)/2)
+	for i := 0; i < len(kv); i++ {
+		switch k := kv[i].(type) {
+		case Field:
+			fields[k.Key] = k.Value()
+		case string:
+			if i+1 == len(kv) {
+				fields[badKey] = k
+				break
+			}
+			fields[k] = kv[i+1]
+			i++
+		default:
+			fields[badKey] = k
+		}
+	}
+	return fields
+}
+
+func (l *Logger) logw(level LoggerLevel, msg string, kv []interface{}) {
+	if l.enabled(level) {
+		l.log(level, msg, fieldsFromPairs(kv))
+	}
+}
+
+// Tracew logs msg with fields built from alternating keys and values:
+//
+//	logger.Infow("user logged in", "user_id", 123, "role", "admin")
+//
+// The same holds for the other *w methods.
+func (l *Logger) Tracew(msg string, keysAndValues ...interface{}) {
+	l.logw(LoggerLevelTrace, msg, keysAndValues)
+}
+
+func (l *Logger) Debugw(msg string, keysAndValues ...interface{}) {
+	l.logw(LoggerLevelDebug, msg, keysAndValues)
+}
+
+func (l *Logger) Infow(msg string, keysAndValues ...interface{}) {
+	l.logw(LoggerLevelInfo, msg, keysAndValues)
+}
+
+func (l *Logger) Warnw(msg string, keysAndValues ...interface{}) {
+	l.logw(LoggerLevelWarn, msg, keysAndValues)
+}
+
+func (l *Logger) Errorw(msg string, keysAndValues ...interface{}) error {
+	l.logw(LoggerLevelError, msg, keysAndValues)
+	return errors.New(msg)
+}
+
+func (l *Logger) Fatalw(msg string, keysAndValues ...interface{}) {
+	l.Fatal(msg, fieldsFromPairs(keysAndValues))
+}
+
+func (l *Logger) Panicw(msg string, keysAndValues ...interface{}) {
+	l.Panic(msg, fieldsFromPairs(keysAndValues))
+}
+
+func Tracew(msg string, keysAndValues ...interface{}) {
+	defaultLogger.Tracew(msg, keysAndValues...)
+}
+
+func Debugw(msg string, keysAndValues ...interface{}) {
+	defaultLogger.Debugw(msg, keysAndValues...)
+}
+
+func Infow(msg string, keysAndValues ...interface{}) {
+	defaultLogger.Infow(msg, keysAndValues...)
+}
+
+func Warnw(msg string, keysAndValues ...interface{}) {
+	defaultLogger.Warnw(msg, keysAndValues...)
+}
+
+func Errorw(msg string, keysAndValues ...interface{}) error {
+	return defaultLogger.Errorw(msg, keysAndValues...)
+}
+
+func Fatalw(msg string, keysAndValues ...interface{}) {
+	defaultLogger.Fatalw(msg, keysAndValues...)
+}
+
+func Panicw(msg string, keysAndValues ...interface{}) {
+	defaultLogger.Panicw(msg, keysAndValues...)
+}
@@ -0,0 +1,147 @@
+package bayaan
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// foldedKeys restricts p to the given keys compared case-insensitively,
+// falling back to defaults when no keys are given.
+func foldedKeys(keys, defaults []string, p FieldProcessor) FieldProcessor {
+	if len(keys) == 0 {
+		keys = defaults
+	}
+	set := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		set[strings.ToLower(k)] = true
+	}
+	return func(key string, value interface{}) interface{} {
+		if !set[strings.ToLower(key)] {
+			return value
+		}
+		return p(key, value)
+	}
+}
+
+var userAgentBrowsers = []struct{ token, name string }{
+	{"Edg/", "Edge"},
+	{"OPR/", "Opera"},
+	{"Firefox/", "Firefox"},
+	{"FxiOS/", "Firefox"},
+	{"CriOS/", "Chrome"},
+	{"Chrome/", "Chrome"},
+	{"Version/", "Safari"},
+	{"curl/", "curl"},
+	{"Go-http-client/", "Go"},
+	{"python-requests/", "python-requests"},
+}
+
+var userAgentSystems = []struct{ token, name string }{
+	{"Windows", "Windows"},
+	{"Android", "Android"},
+	{"iPhone", "iOS"},
+	{"iPad", "iOS"},
+	{"Mac OS X", "macOS"},
+	{"CrOS", "ChromeOS"},
+	{"Linux", "Linux"},
+}
+
+// summarizeUserAgent condenses a User-Agent string to its browser family,
+// major version and operating system, e.g. "Chrome 120 (Windows)".
+func summarizeUserAgent(ua string) string {
+	lower := strings.ToLower(ua)
+	if strings.Contains(lower, "bot") || strings.Contains(lower, "crawler") || strings.Contains(lower, "spider") {
+		return "Bot"
+	}
+
+	browser := ""
+	for _, b := range userAgentBrowsers {
+		i := strings.Index(ua, b.token)
+		if i < 0 {
+			continue
+		}
+		version := ua[i+len(b.token):]
+		if end := strings.IndexAny(version, ". ;)"); end >= 0 {
+			version = version[:end]
+		}
+		browser = strings.TrimSpace(b.name + " " + version)
+		break
+	}
+	if browser == "" {
+		browser, _, _ = strings.Cut(ua, "/")
+		browser, _, _ = strings.Cut(browser, " ")
+	}
+
+	for _, s := range userAgentSystems {
+		if strings.Contains(ua, s.token) {
+			return browser + " (" + s.name + ")"
+		}
+	}
+	return browser
+}
+
+// summarizeCookies replaces a Cookie or Set-Cookie value with a count.
+func summarizeCookies(header string) string {
+	n := 0
+	for _, part := range strings.Split(header, ";") {
+		if name, _, ok := strings.Cut(part, "="); ok && strings.TrimSpace(name) != "" {
+			n++
+		}
+	}
+	return fmt.Sprintf("%d cookies", n)
+}
+
+// SummarizeUserAgent condenses User-Agent strings in the given fields
+// ("user_agent", "user-agent" and "ua" by default) to their browser
+// family, major version and operating system.
+func SummarizeUserAgent(keys ...string) FieldProcessor {
+	return foldedKeys(keys, []string{"user_agent", "user-agent", "ua"}, func(key string, value interface{}) interface{} {
+		if s, ok := value.(string); ok {
+			return summarizeUserAgent(s)
+		}
+		return value
+	})
+}
+
+// SummarizeCookies replaces cookie headers in the given fields ("cookie",
+// "cookies" and "set-cookie" by default) with the number of cookies.
+func SummarizeCookies(keys ...string) FieldProcessor {
+	return foldedKeys(keys, []string{"cookie", "cookies", "set-cookie"}, func(key string, value interface{}) interface{} {
+		switch v := value.(type) {
+		case string:
+			return summarizeCookies(v)
+		case []string:
+			return summarizeCookies(strings.Join(v, ";"))
+		}
+		return value
+	})
+}
+
+// SummarizeHeaders rewrites http.Header field values: User-Agent is
+// summarized, Cookie and Set-Cookie are replaced by a count, and
+// Authorization keeps only its scheme. Every field holding an http.Header
+// is rewritten when no keys are given.
+func SummarizeHeaders(keys ...string) FieldProcessor {
+	return onlyKeys(keys, func(key string, value interface{}) interface{} {
+		h, ok := value.(http.Header)
+		if !ok {
+			return value
+		}
+		out := make(http.Header, len(h))
+		for name, values := range h {
+			switch http.CanonicalHeaderKey(name) {
+			case "User-Agent":
+				out[name] = []string{summarizeUserAgent(strings.Join(values, " "))}
+			case "Cookie", "Set-Cookie":
+				out[name] = []string{summarizeCookies(strings.Join(values, ";"))}
+			case "Authorization", "Proxy-Authorization":
+				scheme, _, _ := strings.Cut(strings.Join(values, " "), " ")
+				out[name] = []string{scheme + " ***"}
+			default:
+				out[name] = values
+			}
+		}
+		return out
+	})
+}
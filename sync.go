@@ -0,0 +1,21 @@
+package bayaan
+
+// WithSync makes the logger write each entry on the calling goroutine
+// instead of handing it to the background writer. Output is then visible as
+// soon as the log call returns and nothing is lost if the program exits
+// without Close, at the cost of callers waiting on slow outputs. Useful for
+// CLIs and tests.
+func WithSync() LoggerOption {
+	return func(l *Logger) {
+		l.state.inline = true
+	}
+}
+
+// writeInline writes entry on the calling goroutine, one caller at a time.
+// Like the writer goroutine it writes through the logger NewLogger built,
+// whose level, dedup and ring buffer apply to every derived logger.
+func (l *Logger) writeInline(entry logEntry) {
+	l.state.mu.Lock()
+	defer l.state.mu.Unlock()
+	l.state.owner.writeLog(entry)
+}
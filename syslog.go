@@ -0,0 +1,296 @@
+package bayaan
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type SyslogFormat int
+
+const (
+	RFC5424 SyslogFormat = iota
+	RFC3164
+)
+
+// Syslog facilities, as defined by RFC 5424.
+const (
+	FacilityKern   = 0
+	FacilityUser   = 1
+	FacilityDaemon = 3
+	FacilityAuth   = 4
+	FacilityLocal0 = 16
+	FacilityLocal1 = 17
+	FacilityLocal2 = 18
+	FacilityLocal3 = 19
+	FacilityLocal4 = 20
+	FacilityLocal5 = 21
+	FacilityLocal6 = 22
+	FacilityLocal7 = 23
+)
+
+// syslogSD is the structured data element fields are written under.
+const syslogSD = "fields@32473"
+
+var syslogSeverities = map[LoggerLevel]int{
+	LoggerLevelTrace: 7, // debug
+	LoggerLevelDebug: 7, // debug
+	LoggerLevelInfo:  6, // informational
+	LoggerLevelWarn:  4, // warning
+	LoggerLevelError: 3, // error
+	LoggerLevelFatal: 2, // critical
+	LoggerLevelPanic: 1, // alert
+}
+
+// syslogSeverity returns the severity for level. Registered levels take
+// that of the closest built-in level below them, except that those between
+// INFO and WARN are notices and those above PANIC emergencies.
+func syslogSeverity(level LoggerLevel) int {
+	if severity, ok := syslogSeverities[level]; ok {
+		return severity
+	}
+	switch {
+	case level.AtLeast(LoggerLevelPanic):
+		return 0 // emergency
+	case level.AtLeast(LoggerLevelInfo) && !level.AtLeast(LoggerLevelWarn):
+		return 5 // notice
+	}
+	for b := LoggerLevelFatal; b > LoggerLevelTrace; b-- {
+		if level.AtLeast(b) {
+			return syslogSeverities[b]
+		}
+	}
+	return 7 // debug
+}
+
+// SyslogSink sends entries to a syslog daemon, either over the local
+// socket or to a remote server via UDP or TCP. In RFC 5424 mode fields are
+// sent as structured data; in RFC 3164 mode they follow the message as
+// key=value pairs.
+type SyslogSink struct {
+	network  string
+	addr     string
+	format   SyslogFormat
+	facility int
+	tag      string
+	hostname string
+
+	mu     sync.Mutex
+	conn   net.Conn
+	stream bool // conn is a stream, so messages are framed
+	closed bool
+}
+
+type SyslogOption func(*SyslogSink)
+
+func WithSyslogFormat(format SyslogFormat) SyslogOption {
+	return func(s *SyslogSink) {
+		s.format = format
+	}
+}
+
+func WithSyslogFacility(facility int) SyslogOption {
+	return func(s *SyslogSink) {
+		s.facility = facility
+	}
+}
+
+// WithSyslogTag sets the APP-NAME (RFC 5424) or TAG (RFC 3164). It
+// defaults to the executable name.
+func WithSyslogTag(tag string) SyslogOption {
+	return func(s *SyslogSink) {
+		s.tag = tag
+	}
+}
+
+// NewSyslogSink connects to the syslog daemon at addr over network ("udp",
+// "tcp", "unix" or "unixgram"). An empty network and addr use the local
+// syslog socket.
+func NewSyslogSink(network, addr string, options ...SyslogOption) (*SyslogSink, error) {
+	hostname, _ := os.Hostname()
+	s := &SyslogSink{
+		network:  network,
+		addr:     addr,
+		facility: FacilityUser,
+		tag:      filepath.Base(os.Args[0]),
+		hostname: hostname,
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	if err := s.connect(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *SyslogSink) connect() error {
+	if s.network != "" {
+		conn, err := net.DialTimeout(s.network, s.addr, 5*time.Second)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+		s.stream = streamNetwork(s.network)
+		return nil
+	}
+
+	for _, path := range []string{"/dev/log", "/var/run/syslog", "/var/run/log"} {
+		for _, network := range []string{"unixgram", "unix"} {
+			if conn, err := net.Dial(network, path); err == nil {
+				s.conn = conn
+				s.stream = streamNetwork(network)
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("bayaan: no local syslog socket found")
+}
+
+// streamNetwork reports whether network carries a byte stream rather than
+// datagrams.
+func streamNetwork(network string) bool {
+	switch network {
+	case "tcp", "tcp4", "tcp6", "unix":
+		return true
+	}
+	return false
+}
+
+func (s *SyslogSink) WriteEntry(e *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return fmt.Errorf("bayaan: syslog sink is closed")
+	}
+	if s.conn == nil {
+		if err := s.connect(); err != nil {
+			return err
+		}
+	}
+	if _, err := s.conn.Write(s.message(e)); err != nil {
+		// The daemon may have restarted; reconnect once and retry. The local
+		// socket found may differ, so the message is built again.
+		s.conn.Close()
+		s.conn = nil
+		if err := s.connect(); err != nil {
+			return err
+		}
+		_, err = s.conn.Write(s.message(e))
+		return err
+	}
+	return nil
+}
+
+// message formats e, framed for the current connection. s.mu must be held.
+func (s *SyslogSink) message(e *Entry) []byte {
+	pri := s.facility*8 + syslogSeverity(e.Level)
+
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	b := &strings.Builder{}
+	if s.format == RFC3164 {
+		// Newline framing on streams: escape line breaks so that logged
+		// input cannot start records of its own.
+		fmt.Fprintf(b, "<%d>%s %s %s[%d]: ", pri, e.Time.Format(time.Stamp), s.hostname, s.tag, os.Getpid())
+		line := appendEscaped(nil, e.Message)
+		for _, k := range keys {
+			line = append(line, ' ')
+			line = appendEscaped(line, k)
+			line = append(line, '=')
+			line = appendEscaped(line, sprint(e.Fields[k]))
+		}
+		b.Write(line)
+		if s.stream {
+			b.WriteByte('\n')
+		}
+		return []byte(b.String())
+	}
+
+	fmt.Fprintf(b, "<%d>1 %s %s %s %d %s ", pri, e.Time.Format(time.RFC3339Nano),
+		syslogHeader(s.hostname), syslogHeader(s.tag), os.Getpid(), e.Level)
+	if len(keys) == 0 {
+		b.WriteByte('-')
+	} else {
+		b.WriteString("[" + syslogSD)
+		for _, k := range keys {
+			fmt.Fprintf(b, " %s=\"%s\"", syslogParamName(k), syslogParamValue(sprint(e.Fields[k])))
+		}
+		b.WriteByte(']')
+	}
+	b.WriteString(" " + e.Message)
+
+	if s.stream {
+		// RFC 6587 octet counting.
+		return []byte(fmt.Sprintf("%d %s", b.Len(), b.String()))
+	}
+	return []byte(b.String())
+}
+
+func syslogHeader(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+func syslogParamName(s string) string {
+	name := strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, s)
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	return name
+}
+
+func syslogParamValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(s)
+}
+
+// Close closes the connection. Entries written afterwards are rejected
+// with an error.
+func (s *SyslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// WithSyslogOutput adds a SyslogSink. See NewSyslogSink for the meaning of
+// network and addr.
+func WithSyslogOutput(network, addr string, options ...SyslogOption) LoggerOption {
+	return func(l *Logger) {
+		s, err := NewSyslogSink(network, addr, options...)
+		if err != nil {
+			l.warnf("Logger could not connect to syslog: %v", err)
+			return
+		}
+		WithSink(s, true)(l)
+	}
+}
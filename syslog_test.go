@@ -0,0 +1,83 @@
+package bayaan
+
+import (
+	"bufio"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Levels can only be registered once, so the tests' levels are registered
+// when the package loads.
+var (
+	syslogVerbose   = RegisterLevel("SYSLOG_VERBOSE", LoggerLevelTrace, "")
+	syslogNotice    = RegisterLevel("SYSLOG_NOTICE", LoggerLevelInfo, "")
+	syslogSevere    = RegisterLevel("SYSLOG_SEVERE", LoggerLevelError, "")
+	syslogEmergency = RegisterLevel("SYSLOG_EMERGENCY", LoggerLevelPanic, "")
+)
+
+func TestSyslogSeverityOfRegisteredLevels(t *testing.T) {
+	tests := []struct {
+		level LoggerLevel
+		want  int
+	}{
+		{syslogVerbose, 7},
+		{syslogNotice, 5},
+		{syslogSevere, 3},
+		{syslogEmergency, 0},
+		{LoggerLevelWarn, 4},
+	}
+	for _, tt := range tests {
+		if got := syslogSeverity(tt.level); got != tt.want {
+			t.Errorf("syslogSeverity(%v) = %d, want %d", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestSyslogFramesUnixStreams(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "syslog.sock")
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skip("unix sockets unavailable:", err)
+	}
+	defer ln.Close()
+	lines := make(chan string, 2)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewScanner(conn)
+		for r.Scan() {
+			lines <- r.Text()
+		}
+	}()
+
+	s, err := NewSyslogSink("unix", path, WithSyslogFormat(RFC3164))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range []string{"first", "second"} {
+		if err := s.WriteEntry(&Entry{Level: LoggerLevelInfo, Time: time.Now(), Message: msg}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, want := range []string{"first", "second"} {
+		select {
+		case line := <-lines:
+			if !strings.HasSuffix(line, ": "+want) {
+				t.Errorf("line = %q, want it to end with %q", line, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no line for %q", want)
+		}
+	}
+
+	s.Close()
+	if err := s.WriteEntry(&Entry{Level: LoggerLevelInfo, Time: time.Now(), Message: "late"}); err == nil {
+		t.Error("WriteEntry after Close returned nil, want an error")
+	}
+}
@@ -0,0 +1,210 @@
+// Package tail follows bayaan-format log files and replays their entries
+// into a Logger, persisting its position so nothing is lost across restarts.
+package tail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ahmedsat/bayaan"
+)
+
+// Tailer follows a single log file. Entries are delivered at least once:
+// the checkpoint only moves past an entry after the logger has written it.
+type Tailer struct {
+	path         string
+	checkpoint   string
+	pollInterval time.Duration
+	timeFormat   string
+	logger       *bayaan.Logger
+
+	file   *os.File
+	offset int64
+}
+
+type Option func(*Tailer)
+
+// WithCheckpoint sets the file the read offset is persisted to. It
+// defaults to the tailed path with an ".offset" suffix.
+func WithCheckpoint(path string) Option {
+	return func(t *Tailer) {
+		t.checkpoint = path
+	}
+}
+
+func WithPollInterval(d time.Duration) Option {
+	return func(t *Tailer) {
+		t.pollInterval = d
+	}
+}
+
+// WithTimeFormat sets the layout the tailed file was written with.
+func WithTimeFormat(format string) Option {
+	return func(t *Tailer) {
+		t.timeFormat = format
+	}
+}
+
+func New(path string, logger *bayaan.Logger, options ...Option) *Tailer {
+	t := &Tailer{
+		path:         path,
+		checkpoint:   path + ".offset",
+		pollInterval: time.Second,
+		timeFormat:   bayaan.DefaultTimeFormat,
+		logger:       logger,
+	}
+
+	for _, option := range options {
+		option(t)
+	}
+
+	return t
+}
+
+// Run follows the file until ctx is cancelled. It survives the file being
+// missing, rotated (renamed and recreated) or truncated.
+func (t *Tailer) Run(ctx context.Context) error {
+	t.offset = t.loadCheckpoint()
+	defer func() {
+		if t.file != nil {
+			t.file.Close()
+		}
+	}()
+
+	for {
+		if err := t.poll(); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(t.pollInterval):
+		}
+	}
+}
+
+func (t *Tailer) poll() error {
+	if t.file == nil {
+		f, err := os.Open(t.path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		t.file = f
+	}
+
+	info, err := t.file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() < t.offset {
+		// Truncated in place: start over from the beginning.
+		t.offset = 0
+	}
+
+	if err := t.drain(); err != nil {
+		return err
+	}
+
+	// Once the old file is drained, switch to whatever now lives at path.
+	current, err := os.Stat(t.path)
+	if err == nil && !os.SameFile(info, current) {
+		t.file.Close()
+		t.file = nil
+		t.offset = 0
+		return t.saveCheckpoint()
+	}
+	return nil
+}
+
+// maxChunk bounds how much of the file is decoded per read.
+const maxChunk = 4 << 20
+
+func (t *Tailer) drain() error {
+	for {
+		if _, err := t.file.Seek(t.offset, io.SeekStart); err != nil {
+			return err
+		}
+		data, err := io.ReadAll(io.LimitReader(t.file, maxChunk))
+		if err != nil {
+			return err
+		}
+		full := len(data) == maxChunk
+
+		// Only consume complete entries; a partially written one waits for
+		// the next read.
+		end := completeLength(data, full)
+		if end == 0 {
+			return nil
+		}
+		data = data[:end]
+
+		dec := bayaan.NewDecoder(bytes.NewReader(data))
+		dec.TimeFormat = t.timeFormat
+		for {
+			e, err := dec.Decode()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			t.logger.Replay(e)
+		}
+
+		t.offset += int64(len(data))
+		if err := t.saveCheckpoint(); err != nil {
+			return err
+		}
+		if !full {
+			return nil
+		}
+	}
+}
+
+// completeLength returns how many bytes of data hold whole entries. At the
+// end of the file that is everything up to the last newline, since bayaan
+// writes each entry in one call; inside a larger file it stops before the
+// last entry header, which may continue past the chunk.
+func completeLength(data []byte, full bool) int {
+	end := bytes.LastIndexByte(data, '\n') + 1
+	if !full {
+		return end
+	}
+	for i := end - 2; i >= 0; i-- {
+		if data[i] == '\n' && data[i+1] != ' ' && data[i+1] != '\n' {
+			return i + 1
+		}
+	}
+	// A single entry larger than the chunk; consume it as is.
+	return end
+}
+
+func (t *Tailer) loadCheckpoint() int64 {
+	data, err := os.ReadFile(t.checkpoint)
+	if err != nil {
+		return 0
+	}
+	offset, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return offset
+}
+
+func (t *Tailer) saveCheckpoint() error {
+	tmp := t.checkpoint + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.FormatInt(t.offset, 10)+"\n"), 0644); err != nil {
+		return fmt.Errorf("tail: saving checkpoint: %w", err)
+	}
+	return os.Rename(tmp, t.checkpoint)
+}
@@ -0,0 +1,35 @@
+package bayaan
+
+import "bytes"
+
+// TB is the part of testing.TB NewTestLogger uses.
+type TB interface {
+	Log(args ...interface{})
+	Cleanup(func())
+}
+
+// NewTestLogger returns a synchronous logger writing every entry, at all
+// levels, through t.Log, so output is attributed to the test that produced
+// it and shown when it fails or runs with -v. The logger is closed when the
+// test ends. options are applied after the defaults and may, for example,
+// raise the level or set a formatter; added outputs write alongside t.
+func NewTestLogger(t TB, options ...LoggerOption) *Logger {
+	defaults := []LoggerOption{
+		WithSync(),
+		WithLevel(LoggerLevelTrace),
+		WithOutput(testWriter{t}, false, false),
+	}
+	l := NewLogger(append(defaults, options...)...)
+	t.Cleanup(l.Close)
+	return l
+}
+
+// testWriter logs each formatted entry through t.
+type testWriter struct {
+	t TB
+}
+
+func (w testWriter) Write(p []byte) (int, error) {
+	w.t.Log(string(bytes.TrimSuffix(p, []byte("\n"))))
+	return len(p), nil
+}
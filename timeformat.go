@@ -0,0 +1,71 @@
+package bayaan
+
+import (
+	"strconv"
+	"time"
+)
+
+// Numeric time formats, accepted wherever a time layout is, such as
+// WithTimeFormat, TextFormatter.TimeFormat and JSONFormatter.TimeFormat.
+// They render the time as an integer count since the Unix epoch, which
+// JSONFormatter writes as a number. Shippers usually want milliseconds.
+const (
+	TimeFormatUnix      = "unix"
+	TimeFormatUnixMilli = "unixmilli"
+	TimeFormatUnixMicro = "unixmicro"
+	TimeFormatUnixNano  = "unixnano"
+)
+
+// epoch returns t as a count since the Unix epoch in the unit of a numeric
+// format, and false for any other layout.
+func epoch(t time.Time, format string) (int64, bool) {
+	switch format {
+	case TimeFormatUnix:
+		return t.Unix(), true
+	case TimeFormatUnixMilli:
+		return t.UnixMilli(), true
+	case TimeFormatUnixMicro:
+		return t.UnixMicro(), true
+	case TimeFormatUnixNano:
+		return t.UnixNano(), true
+	}
+	return 0, false
+}
+
+// appendTime appends t rendered with format, a layout or a numeric format.
+func appendTime(dst []byte, t time.Time, format string) []byte {
+	if n, ok := epoch(t, format); ok {
+		return strconv.AppendInt(dst, n, 10)
+	}
+	return t.AppendFormat(dst, format)
+}
+
+// parseTime is time.ParseInLocation that also reads numeric formats.
+func parseTime(format, value string, loc *time.Location) (time.Time, error) {
+	var t time.Time
+	switch format {
+	case TimeFormatUnix, TimeFormatUnixMilli, TimeFormatUnixMicro, TimeFormatUnixNano:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		switch format {
+		case TimeFormatUnix:
+			t = time.Unix(n, 0)
+		case TimeFormatUnixMilli:
+			t = time.UnixMilli(n)
+		case TimeFormatUnixMicro:
+			t = time.UnixMicro(n)
+		default:
+			t = time.Unix(0, n)
+		}
+		if loc != nil {
+			t = t.In(loc)
+		}
+		return t, nil
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	return time.ParseInLocation(format, value, loc)
+}
@@ -0,0 +1,77 @@
+package bayaan
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// errOutputBusy is reported for entries dropped because an earlier write to
+// the same output timed out and has not returned yet.
+var errOutputBusy = errors.New("bayaan: output blocked by a timed-out write")
+
+// errWriteAbandoned is returned when a write outlives its timeout and is
+// left running in the background.
+var errWriteAbandoned = fmt.Errorf("bayaan: write abandoned: %w", os.ErrDeadlineExceeded)
+
+// deadliner is implemented by writers that support write deadlines, such as
+// net.Conn and pollable *os.File values (pipes, sockets, terminals).
+type deadliner interface {
+	SetWriteDeadline(t time.Time) error
+}
+
+// WithWriteTimeout bounds every output and sink write to d, so a single
+// blocked write (a dead NFS mount, a stalled TCP peer) cannot freeze the
+// logging pipeline. Writers supporting SetWriteDeadline get a deadline;
+// anything else is written from a helper goroutine that is abandoned once
+// d passes. While an abandoned write is still blocked, entries for that
+// output are dropped instead of queueing up behind it. Zero disables the
+// timeout.
+func WithWriteTimeout(d time.Duration) LoggerOption {
+	return func(l *Logger) {
+		l.mu.Lock()
+		l.timeout = d
+		l.mu.Unlock()
+	}
+}
+
+// deliver runs write against out, honoring timeout if it is positive.
+// Timeouts are reported as errors matching os.ErrDeadlineExceeded.
+func deliver(out output, timeout time.Duration, write func() (int, error)) (int, error) {
+	if timeout <= 0 || out.health == nil {
+		return write()
+	}
+	if out.health.busy.Load() {
+		return 0, errOutputBusy
+	}
+
+	if d, ok := out.writer.(deadliner); ok && out.sink == nil {
+		if err := d.SetWriteDeadline(time.Now().Add(timeout)); err == nil {
+			n, err := write()
+			d.SetWriteDeadline(time.Time{})
+			return n, err
+		}
+	}
+
+	type result struct {
+		n   int
+		err error
+	}
+	res := make(chan result, 1)
+	out.health.busy.Store(true)
+	go func() {
+		n, err := write()
+		out.health.busy.Store(false)
+		res <- result{n, err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case r := <-res:
+		return r.n, r.err
+	case <-timer.C:
+		return 0, errWriteAbandoned
+	}
+}
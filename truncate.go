@@ -0,0 +1,163 @@
+package bayaan
+
+import (
+	"math/big"
+	"time"
+	"unicode/utf8"
+)
+
+// ellipsis marks where a truncated value was cut.
+const ellipsis = "…"
+
+// minTruncatedLength is how short WithMaxEntrySize cuts any one value.
+const minTruncatedLength = 64
+
+type sizeLimits struct {
+	field int // longest field value, 0 for no limit
+	entry int // largest entry, 0 for no limit
+}
+
+// WithMaxFieldLength cuts field values longer than n bytes to n bytes and
+// an ellipsis, and marks the entry with truncated=true. Strings are
+// measured as they are; other values, groups aside, as they print with
+// fmt, and are replaced by their cut rendering when too long. Numbers,
+// booleans and times are never cut. Zero removes the limit.
+func WithMaxFieldLength(n int) LoggerOption {
+	return func(l *Logger) {
+		l.mu.Lock()
+		l.limits.field = n
+		l.mu.Unlock()
+	}
+}
+
+// WithMaxEntrySize bounds the message and field values of an entry to
+// about n bytes in total, measured as in WithMaxFieldLength, keys
+// included. The largest of them are cut first, none below 64 bytes, and
+// the entry is marked with truncated=true. Zero removes the limit.
+func WithMaxEntrySize(n int) LoggerOption {
+	return func(l *Logger) {
+		l.mu.Lock()
+		l.limits.entry = n
+		l.mu.Unlock()
+	}
+}
+
+// apply enforces the limits on an entry's message and resolved fields.
+func (s sizeLimits) apply(msg *string, fields Fields) {
+	truncated := false
+	if s.field > 0 {
+		truncated = truncateFields(fields, s.field, 0)
+	}
+	if s.entry > 0 && shrinkEntry(msg, fields, s.entry) {
+		truncated = true
+	}
+	if truncated {
+		fields["truncated"] = true
+	}
+}
+
+func truncateFields(fields Fields, n, depth int) bool {
+	truncated := false
+	for k, v := range fields {
+		if group, ok := v.(Fields); ok {
+			if depth < maxGroupDepth {
+				if cut, ok := truncateGroup(group, n, depth+1); ok {
+					fields[k] = cut
+					truncated = true
+				}
+			}
+			continue
+		}
+		s, ok := renderedValue(v)
+		if ok && len(s) > n {
+			fields[k] = truncateString(s, n)
+			truncated = true
+		}
+	}
+	return truncated
+}
+
+// truncateGroup returns a truncated copy of group, which may be shared with
+// other entries, and false when nothing in it needs cutting.
+func truncateGroup(group Fields, n, depth int) (Fields, bool) {
+	copied := make(Fields, len(group))
+	for k, v := range group {
+		copied[k] = v
+	}
+	if !truncateFields(copied, n, depth) {
+		return group, false
+	}
+	return copied, true
+}
+
+// shrinkEntry cuts the largest of msg and the top-level field values until
+// the entry fits in n bytes or nothing is left to cut.
+func shrinkEntry(msg *string, fields Fields, n int) bool {
+	total := len(*msg)
+	sizes := make(map[string]int, len(fields))
+	for k, v := range fields {
+		size := len(k)
+		if s, ok := renderedValue(v); ok {
+			size += len(s)
+			sizes[k] = len(s)
+		} else {
+			size += len(sprint(v))
+		}
+		total += size
+	}
+
+	truncated := false
+	for total > n {
+		largest, size := "", len(*msg)
+		for k, s := range sizes {
+			if s > size {
+				largest, size = k, s
+			}
+		}
+		if size <= minTruncatedLength {
+			break
+		}
+		keep := size - (total - n)
+		if keep < minTruncatedLength {
+			keep = minTruncatedLength
+		}
+		if largest == "" {
+			*msg = truncateString(*msg, keep)
+		} else {
+			s, _ := renderedValue(fields[largest])
+			fields[largest] = truncateString(s, keep)
+			sizes[largest] = keep
+		}
+		total -= size - keep
+		truncated = true
+	}
+	return truncated
+}
+
+// renderedValue returns v as the text that is measured against the limits,
+// and false for values that are never cut: numbers, booleans, times and
+// groups.
+func renderedValue(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case nil, Fields, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64,
+		float32, float64, time.Time, time.Duration, *big.Int, big.Int:
+		return "", false
+	case error:
+		return errorText(v), true
+	}
+	return sprint(v), true
+}
+
+// truncateString cuts s to at most n bytes, on a rune boundary, and adds an
+// ellipsis.
+func truncateString(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + ellipsis
+}
@@ -0,0 +1,94 @@
+package bayaan
+
+import (
+	"crypto/rand"
+	"sync"
+	"time"
+)
+
+// crockford is the Base32 alphabet of ULIDs, without I, L, O and U.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULIDGenerator generates ULIDs: 26-character identifiers made of a
+// millisecond timestamp and 80 random bits, which sort by creation time.
+// IDs made within the same millisecond increment the random part, so they
+// sort in the order they were made too. The zero value is ready to use,
+// and is safe for concurrent use; pass it to WithIDGenerator to have NewID
+// return ULIDs.
+type ULIDGenerator struct {
+	mu   sync.Mutex
+	ms   uint64
+	last [16]byte
+}
+
+// NewID returns a ULID for the current time.
+func (g *ULIDGenerator) NewID() string {
+	return g.next(time.Now())
+}
+
+func (g *ULIDGenerator) next(t time.Time) string {
+	ms := uint64(t.UnixMilli())
+
+	g.mu.Lock()
+	if ms <= g.ms {
+		// Same millisecond, or the clock stepped back: keep sorting after
+		// the previous ID.
+		g.increment()
+	} else {
+		g.setTime(ms)
+		rand.Read(g.last[6:])
+	}
+	id := g.last
+	g.mu.Unlock()
+
+	return encodeULID(id)
+}
+
+// increment adds one to the random part, moving on to the next
+// millisecond when it overflows.
+func (g *ULIDGenerator) increment() {
+	for i := 15; i >= 6; i-- {
+		g.last[i]++
+		if g.last[i] != 0 {
+			return
+		}
+	}
+	g.setTime(g.ms + 1)
+}
+
+func (g *ULIDGenerator) setTime(ms uint64) {
+	g.ms = ms
+	for i := 0; i < 6; i++ {
+		g.last[i] = byte(ms >> (40 - 8*i))
+	}
+}
+
+// encodeULID writes the 128 bits of id as 26 Base32 digits, the first
+// holding the top 3 bits.
+func encodeULID(id [16]byte) string {
+	var out [26]byte
+	var acc uint32
+	bits := 2 // 130 bits of output for 128 of input: two leading zero bits
+	n := 0
+	for _, b := range id {
+		acc = acc<<8 | uint32(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			out[n] = crockford[(acc>>bits)&31]
+			n++
+		}
+	}
+	return string(out[:])
+}
+
+// WithEntryIDs stamps every entry with an "entry_id" field holding a ULID
+// taken from its timestamp, so a line can be quoted in a ticket and found
+// again in every sink that received the same entry.
+func WithEntryIDs() LoggerOption {
+	return func(l *Logger) {
+		l.mu.Lock()
+		l.entryIDs = &ULIDGenerator{}
+		l.mu.Unlock()
+	}
+}
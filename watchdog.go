@@ -0,0 +1,128 @@
+package bayaan
+
+import (
+	"bytes"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// writerState tracks the writer goroutine's progress. It is shared by a
+// logger and everything derived from it.
+type writerState struct {
+	goroutine atomic.Int64
+	busySince atomic.Int64 // unix nanos when the current entry started, 0 when idle
+	failover  atomic.Bool
+	closed    atomic.Bool   // Close was called; entries go to stderr
+	quit      chan struct{} // closed by Close to stop the writer
+	closeOnce sync.Once
+	degraded  atomic.Bool // every output is failing; see degrade
+	dropped   atomic.Uint64
+	entries   [LoggerLevelsCount]atomic.Uint64
+	custom    sync.Map     // registered level -> *atomic.Uint64 entries written
+	owner     *Logger      // the logger NewLogger built, which writes entries
+	inline    bool         // WithSync: callers write entries themselves
+	mu        sync.Mutex   // serializes inline writes
+	sending   sync.RWMutex // held by senders so none queues after Close
+}
+
+func goroutineID() int64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	id, _ := strconv.ParseInt(string(buf[:bytes.IndexByte(buf, ' ')]), 10, 64)
+	return id
+}
+
+// goroutineStack returns the stack of the goroutine with the given ID.
+func goroutineStack(id int64) string {
+	buf := make([]byte, 1<<20)
+	buf = buf[:runtime.Stack(buf, true)]
+	header := []byte("goroutine " + strconv.FormatInt(id, 10) + " [")
+	for _, block := range bytes.Split(buf, []byte("\n\n")) {
+		if bytes.HasPrefix(block, header) {
+			return string(block)
+		}
+	}
+	return "goroutine " + strconv.FormatInt(id, 10) + " not found"
+}
+
+// WithWatchdog reports, through the diagnostics writer and with the
+// writer goroutine's stack, when writing a single entry takes longer than
+// timeout, which usually means an output is blocked. With failover,
+// entries are written synchronously to stderr in the meantime instead of
+// piling up in the queue. The timeout must be positive; the watchdog
+// checks a quarter of it apart, at most every millisecond.
+func WithWatchdog(timeout time.Duration, failover bool) LoggerOption {
+	return func(l *Logger) {
+		if timeout <= 0 {
+			l.warnf("Logger watchdog timeout must be positive, got %v; watchdog disabled", timeout)
+			return
+		}
+		stop := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			ticker := time.NewTicker(max(timeout/4, time.Millisecond))
+			defer ticker.Stop()
+
+			var stalled int64
+			for {
+				select {
+				case <-stop:
+					return
+				case <-ticker.C:
+				}
+
+				since := l.state.busySince.Load()
+				if since != 0 && since == stalled {
+					continue
+				}
+				if stalled != 0 {
+					stalled = 0
+					l.state.failover.Store(false)
+					l.warnf("Logger writer recovered")
+				}
+				if since != 0 && time.Since(time.Unix(0, since)) > timeout {
+					stalled = since
+					l.state.failover.Store(failover)
+					l.warnf("Logger writer stalled for over %s:\n%s", timeout, goroutineStack(l.state.goroutine.Load()))
+				}
+			}
+		}()
+
+		l.mu.Lock()
+		l.stops = append(l.stops, func() {
+			close(stop)
+			<-done
+		})
+		l.mu.Unlock()
+	}
+}
+
+// writeFailover writes an entry straight to stderr while the writer
+// goroutine is stalled.
+func (l *Logger) writeFailover(level LoggerLevel, msg string, fields Fields) {
+	min := l.minLevel()
+	l.mu.RLock()
+	location := l.location
+	redactKeys := l.redact
+	l.mu.RUnlock()
+	if !level.AtLeast(min) {
+		return
+	}
+	e := &Entry{Level: level, Time: l.now(), Message: msg, Fields: l.Fields()}
+	if location != nil {
+		e.Time = e.Time.In(location)
+	}
+	for k, v := range fields {
+		e.Fields[k] = v
+	}
+	for k, v := range e.Fields {
+		e.Fields[k] = redact(k, resolve(v), redactKeys, 0)
+	}
+	os.Stderr.WriteString(formatText(e, DefaultTimeFormat) + "\n")
+}
@@ -0,0 +1,30 @@
+package bayaan_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahmedsat/bayaan"
+)
+
+func TestWatchdogRejectsNonPositiveTimeouts(t *testing.T) {
+	for _, timeout := range []time.Duration{0, -time.Second} {
+		var diag bytes.Buffer
+		sink := &entrySink{}
+		l := bayaan.NewLogger(bayaan.WithSink(sink, false), bayaan.WithDiagnostics(&diag),
+			bayaan.WithWatchdog(timeout, true))
+		l.Info("still logging", nil)
+		l.Close()
+
+		if !strings.Contains(diag.String(), "watchdog disabled") {
+			t.Errorf("timeout %v: diagnostics = %q, want a warning", timeout, diag.String())
+		}
+		sink.mu.Lock()
+		if len(sink.entries) != 1 {
+			t.Errorf("timeout %v: %d entries written, want 1", timeout, len(sink.entries))
+		}
+		sink.mu.Unlock()
+	}
+}
@@ -0,0 +1,196 @@
+package bayaan
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// DefaultWebhookTemplate renders a Slack-compatible message.
+const DefaultWebhookTemplate = `{"text": {{ printf "*%s*: %s%s%s" .Level .Message .FieldsText .SuppressedText | json }}}`
+
+// WebhookMessage is the data available to webhook payload templates.
+type WebhookMessage struct {
+	*Entry
+	// Suppressed counts entries dropped by rate limiting since the last post.
+	Suppressed int
+}
+
+// FieldsText renders the fields as one "key: value" line each.
+func (m WebhookMessage) FieldsText() string {
+	keys := make([]string, 0, len(m.Fields))
+	for k := range m.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	b := &strings.Builder{}
+	for _, k := range keys {
+		fmt.Fprintf(b, "\n`%s`: %s", k, sprint(m.Fields[k]))
+	}
+	return b.String()
+}
+
+func (m WebhookMessage) SuppressedText() string {
+	if m.Suppressed == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\n_(%d more suppressed)_", m.Suppressed)
+}
+
+// WebhookSink posts entries at or above a level to a webhook, such as a
+// Slack incoming webhook. Posts are rate limited and sent in the
+// background; Close waits for queued ones.
+type WebhookSink struct {
+	url      string
+	minLevel LoggerLevel
+	template *template.Template
+	limit    int
+	per      time.Duration
+	client   *http.Client
+
+	mu          sync.Mutex
+	windowStart time.Time
+	sent        int
+	suppressed  int
+	closed      bool // guards queue against Close
+
+	queue chan []byte
+	wg    sync.WaitGroup
+}
+
+type WebhookOption func(*WebhookSink) error
+
+// WithWebhookTemplate sets a text/template producing the request body from
+// a WebhookMessage. The "json" function quotes a value as a JSON string.
+func WithWebhookTemplate(text string) WebhookOption {
+	return func(s *WebhookSink) error {
+		t, err := template.New("webhook").Funcs(webhookFuncs).Parse(text)
+		if err != nil {
+			return err
+		}
+		s.template = t
+		return nil
+	}
+}
+
+// WithWebhookRateLimit allows at most limit posts per interval. Entries
+// over the limit are counted and reported with the next post.
+func WithWebhookRateLimit(limit int, per time.Duration) WebhookOption {
+	return func(s *WebhookSink) error {
+		s.limit = limit
+		s.per = per
+		return nil
+	}
+}
+
+var webhookFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// NewWebhookSink creates a sink posting entries at or above minLevel to
+// url. By default it sends Slack-style payloads, at most 5 per minute.
+func NewWebhookSink(url string, minLevel LoggerLevel, options ...WebhookOption) (*WebhookSink, error) {
+	s := &WebhookSink{
+		url:      url,
+		minLevel: minLevel,
+		template: template.Must(template.New("webhook").Funcs(webhookFuncs).Parse(DefaultWebhookTemplate)),
+		limit:    5,
+		per:      time.Minute,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		queue:    make(chan []byte, 16),
+	}
+
+	for _, option := range options {
+		if err := option(s); err != nil {
+			return nil, err
+		}
+	}
+
+	go s.run()
+	return s, nil
+}
+
+func (s *WebhookSink) WriteEntry(e *Entry) error {
+	if !e.Level.AtLeast(s.minLevel) {
+		return nil
+	}
+
+	s.mu.Lock()
+	now := e.Time
+	if now.Sub(s.windowStart) >= s.per {
+		s.windowStart = now
+		s.sent = 0
+	}
+	if s.sent >= s.limit {
+		s.suppressed++
+		s.mu.Unlock()
+		return nil
+	}
+	s.sent++
+	suppressed := s.suppressed
+	s.suppressed = 0
+	s.mu.Unlock()
+
+	body := &bytes.Buffer{}
+	if err := s.template.Execute(body, WebhookMessage{Entry: e, Suppressed: suppressed}); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return fmt.Errorf("bayaan: webhook sink closed, dropping alert")
+	}
+	s.wg.Add(1)
+	select {
+	case s.queue <- body.Bytes():
+		return nil
+	default:
+		s.wg.Done()
+		return fmt.Errorf("bayaan: webhook queue full, dropping alert")
+	}
+}
+
+func (s *WebhookSink) run() {
+	for body := range s.queue {
+		if err := s.post(body); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Logger webhook failed: %v\n", err)
+		}
+		s.wg.Done()
+	}
+}
+
+func (s *WebhookSink) post(body []byte) error {
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
+
+// Close waits for queued posts to be sent. Entries written afterwards are
+// dropped with an error.
+func (s *WebhookSink) Close() error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return nil
+}
@@ -0,0 +1,42 @@
+package bayaan_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ahmedsat/bayaan"
+)
+
+// Levels can only be registered once, so the test's levels are registered
+// when the package loads.
+var (
+	webhookNotice   = bayaan.RegisterLevel("WEBHOOK_NOTICE", bayaan.LoggerLevelInfo, "")
+	webhookCritical = bayaan.RegisterLevel("WEBHOOK_CRITICAL", bayaan.LoggerLevelError, "")
+)
+
+func TestWebhookMinLevelWithRegisteredLevels(t *testing.T) {
+	var posts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts.Add(1)
+	}))
+	defer srv.Close()
+
+	s, err := bayaan.NewWebhookSink(srv.URL, bayaan.LoggerLevelWarn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for _, level := range []bayaan.LoggerLevel{webhookNotice, bayaan.LoggerLevelInfo, webhookCritical, bayaan.LoggerLevelError} {
+		if err := s.WriteEntry(&bayaan.Entry{Level: level, Time: now, Message: level.String()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.Close()
+
+	if got := posts.Load(); got != 2 {
+		t.Errorf("posts = %d, want 2 (the levels at or above WARN)", got)
+	}
+}
@@ -0,0 +1,110 @@
+package bayaan
+
+import "errors"
+
+// defaultQueueSize is the number of entries the queue holds below ERROR.
+const defaultQueueSize = 1000
+
+// WithQueueSize sets how many entries the queue holds before new entries
+// below ERROR are dropped, 1000 by default. Another 100 slots are always
+// kept for ERROR and above. It only takes effect in NewLogger.
+func WithQueueSize(n int) LoggerOption {
+	return func(l *Logger) {
+		if l.state.goroutine.Load() != 0 {
+			l.warnf("Logger queue size can only be set when the logger is created")
+			return
+		}
+		if n < 1 {
+			n = 1
+		}
+		l.logChan = make(chan logEntry, n+urgentCapacity)
+	}
+}
+
+// WithWriters writes to outputs from n goroutines instead of one, so a
+// slow or stalled output holds up only the outputs sharing its goroutine.
+// Each goroutine queues as many entries as the logger; when one is full,
+// its outputs miss the entry, which counts as a failed write and in
+// Metrics.OutputDropped, rather than stalling the rest.
+// Outputs are spread over the goroutines in the order they were added;
+// each output still receives entries one at a time and in order. Entries
+// are prepared and formatted on one goroutine as before, and Flush,
+// Reopen and Reconfigure wait for every goroutine to catch up. It only
+// takes effect in NewLogger.
+func WithWriters(n int) LoggerOption {
+	return func(l *Logger) {
+		if l.state.goroutine.Load() != 0 {
+			l.warnf("Logger writers can only be set when the logger is created")
+			return
+		}
+		l.writers = n
+	}
+}
+
+// outputWorkers delivers entries to outputs, each output pinned to one
+// goroutine by its position.
+type outputWorkers struct {
+	queues []chan func()
+}
+
+func startOutputWorkers(n, queueSize int) *outputWorkers {
+	w := &outputWorkers{queues: make([]chan func(), n)}
+	for i := range w.queues {
+		q := make(chan func(), queueSize)
+		w.queues[i] = q
+		go func() {
+			for job := range q {
+				job()
+			}
+		}()
+	}
+	return w
+}
+
+// submit queues job on the goroutine of the output at index i, reporting
+// false when that goroutine is full.
+func (w *outputWorkers) submit(i int, job func()) bool {
+	select {
+	case w.queues[i%len(w.queues)] <- job:
+		return true
+	default:
+		return false
+	}
+}
+
+// errOutputBehind is reported for entries an output missed because its
+// writer goroutine was full.
+var errOutputBehind = errors.New("bayaan: output writer goroutine full")
+
+// submit queues job, the write of an entry to out, the output at index i,
+// or drops it when out's goroutine is full.
+func (l *Logger) submit(i int, out output, job func()) {
+	if l.workers.submit(i, job) {
+		out.health.behind.Store(false)
+		return
+	}
+	out.health.dropped.Add(1)
+	out.health.report(errOutputBehind)
+	if out.health.behind.CompareAndSwap(false, true) {
+		l.warnf("Logger output %s is behind, dropping entries until it catches up", outputName(out))
+	}
+}
+
+// wait returns once every job submitted before the call has run.
+func (w *outputWorkers) wait() {
+	done := make(chan struct{}, len(w.queues))
+	for _, q := range w.queues {
+		q <- func() { done <- struct{}{} }
+	}
+	for range w.queues {
+		<-done
+	}
+}
+
+// stop waits for the submitted jobs, then ends the goroutines.
+func (w *outputWorkers) stop() {
+	w.wait()
+	for _, q := range w.queues {
+		close(q)
+	}
+}
@@ -0,0 +1,122 @@
+package bayaan
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"sync"
+)
+
+// LineWriter is an io.Writer that logs every line written to it as one
+// entry, bridging the standard library's log package and subprocess
+// output into a Logger.
+type LineWriter struct {
+	logger *Logger
+	level  LoggerLevel
+	detect bool
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+// Writer returns a LineWriter logging each line at level.
+func (l *Logger) Writer(level LoggerLevel) *LineWriter {
+	return &LineWriter{logger: l, level: level}
+}
+
+// DetectingWriter returns a LineWriter that picks each line's level from a
+// severity keyword near its start ("ERROR:", "[warn]", "level=debug"),
+// falling back to fallback when there is none.
+func (l *Logger) DetectingWriter(fallback LoggerLevel) *LineWriter {
+	return &LineWriter{logger: l, level: fallback, detect: true}
+}
+
+// StdLogger returns a standard library *log.Logger writing through a
+// DetectingWriter, for packages that only accept one.
+func (l *Logger) StdLogger(fallback LoggerLevel) *log.Logger {
+	return log.New(l.DetectingWriter(fallback), "", 0)
+}
+
+func (w *LineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.logLine(string(bytes.TrimRight(w.buf[:i], "\r")))
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush logs a trailing partial line, if any.
+func (w *LineWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) > 0 {
+		w.logLine(string(w.buf))
+		w.buf = nil
+	}
+}
+
+func (w *LineWriter) Close() error {
+	w.Flush()
+	return nil
+}
+
+func (w *LineWriter) logLine(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	level := w.level
+	if w.detect {
+		level, line = detectLevel(line, w.level)
+	}
+	w.logger.log(level, line, nil)
+}
+
+var severityKeywords = map[string]LoggerLevel{
+	"trace":    LoggerLevelTrace,
+	"debug":    LoggerLevelDebug,
+	"dbg":      LoggerLevelDebug,
+	"info":     LoggerLevelInfo,
+	"notice":   LoggerLevelInfo,
+	"warn":     LoggerLevelWarn,
+	"warning":  LoggerLevelWarn,
+	"error":    LoggerLevelError,
+	"err":      LoggerLevelError,
+	"crit":     LoggerLevelFatal,
+	"critical": LoggerLevelFatal,
+	"fatal":    LoggerLevelFatal,
+	"panic":    LoggerLevelPanic,
+}
+
+// detectLevel looks for a severity keyword among the first few words of
+// line, allowing for a timestamp before it. A keyword that starts the line
+// is removed from the message.
+func detectLevel(line string, fallback LoggerLevel) (LoggerLevel, string) {
+	words := strings.Fields(line)
+	for i, word := range words {
+		if i >= 4 {
+			break
+		}
+		token := strings.ToLower(strings.Trim(word, "[]():|<>"))
+		token = strings.TrimPrefix(token, "level=")
+		level, ok := severityKeywords[token]
+		if !ok {
+			continue
+		}
+		if i == 0 {
+			rest := strings.TrimSpace(strings.TrimPrefix(line, word))
+			if rest != "" {
+				line = rest
+			}
+		}
+		return level, line
+	}
+	return fallback, line
+}